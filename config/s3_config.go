@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+type S3Config struct {
+	DefaultBucket string
+	Region        string
+	// Tipos de conteúdo aceitos no upload (ex.: image/*); vazio aceita qualquer tipo
+	AllowedContentTypes []string
+	// Estratégia de geração da chave: original, uuid, date ou hash
+	KeyStrategy string
+	// Política para chaves já existentes: allow, reject ou rename
+	OverwritePolicy string
+	// Fila consumida pelo leitor de eventos do S3 e tópico usado como destino SNS
+	EventsQueue string
+	EventsTopic string
+	// Chave KMS usada no SSE-KMS quando o upload não informa outra
+	KMSKeyID string
+	// Diretório base permitido para o endpoint de sincronização; vazio desativa o endpoint
+	SyncRoot string
+	// Aplica a política CORS padrão quando a aplicação cria o bucket padrão
+	BootstrapCORS      bool
+	CORSAllowedOrigins []string
+	// Lado maior, em pixels, das miniaturas geradas para imagens enviadas; vazio desativa
+	ThumbnailSizes []int
+}
+
+func GetS3Config() S3Config {
+	return S3Config{
+		DefaultBucket:       getEnv("S3_DEFAULT_BUCKET", "demo-bucket"),
+		Region:              getEnv("S3_DEFAULT_REGION", "sa-east-1"),
+		AllowedContentTypes: getEnvList("S3_ALLOWED_CONTENT_TYPES"),
+		KeyStrategy:         getEnv("S3_KEY_STRATEGY", "original"),
+		OverwritePolicy:     getEnv("S3_OVERWRITE_POLICY", "allow"),
+		EventsQueue:         getEnv("S3_EVENTS_QUEUE", "s3-events"),
+		EventsTopic:         getEnv("S3_EVENTS_TOPIC", "demo-topic"),
+		KMSKeyID:            os.Getenv("S3_KMS_KEY_ID"),
+		SyncRoot:            os.Getenv("S3_SYNC_ROOT"),
+		BootstrapCORS:       getEnv("S3_BOOTSTRAP_CORS", "false") == "true",
+		CORSAllowedOrigins:  getEnvList("S3_CORS_ALLOWED_ORIGINS"),
+		ThumbnailSizes:      getEnvIntList("S3_THUMBNAIL_SIZES", "128,512"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvList(key string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvInt lê um inteiro positivo, usando o padrão quando ausente ou inválido
+func getEnvInt(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// getEnvIntList lê uma lista de inteiros positivos, ignorando valores inválidos
+func getEnvIntList(key, fallback string) []int {
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		raw = fallback
+	}
+
+	values := make([]int, 0)
+	for _, value := range strings.Split(raw, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 {
+			values = append(values, n)
+		}
+	}
+	return values
+}
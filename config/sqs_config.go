@@ -0,0 +1,30 @@
+package config
+
+import "os"
+
+type SQSConfig struct {
+	// Fila usada pelas rotas /sqs/send e /sqs/receive, criada na primeira chamada
+	DefaultQueue string
+	// Recebimentos antes de a mensagem ir para a DLQ, quando a configuração não informa outro valor
+	MaxReceiveCount int
+	// Mensagens por segundo devolvidas da DLQ à fila de origem no redrive
+	RedriveRate int
+	// Filas processadas em segundo plano pelos consumidores da aplicação
+	ConsumerQueues      []string
+	ConsumerConcurrency int
+	ConsumerMaxAttempts int
+	// Fila que recebe as mensagens que esgotaram as tentativas; vazia as descarta
+	ConsumerPoisonQueue string
+}
+
+func GetSQSConfig() SQSConfig {
+	return SQSConfig{
+		DefaultQueue:        getEnv("SQS_DEFAULT_QUEUE", "demo-queue"),
+		MaxReceiveCount:     getEnvInt("SQS_DLQ_MAX_RECEIVE_COUNT", 5),
+		RedriveRate:         getEnvInt("SQS_REDRIVE_RATE", 10),
+		ConsumerQueues:      getEnvList("SQS_CONSUMER_QUEUES"),
+		ConsumerConcurrency: getEnvInt("SQS_CONSUMER_CONCURRENCY", 4),
+		ConsumerMaxAttempts: getEnvInt("SQS_CONSUMER_MAX_ATTEMPTS", 5),
+		ConsumerPoisonQueue: os.Getenv("SQS_CONSUMER_POISON_QUEUE"),
+	}
+}
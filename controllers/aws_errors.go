@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/smithy-go"
+)
+
+// apiErrorCode retorna o código de erro devolvido pela API da AWS (ex.: NoSuchBucket)
+func apiErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+// s3ErrorStatus converte erros do S3 no status HTTP equivalente
+func s3ErrorStatus(err error) int {
+	switch apiErrorCode(err) {
+	case "NoSuchBucket", "NoSuchKey", "NoSuchVersion", "NoSuchLifecycleConfiguration", "NoSuchCORSConfiguration", "NotFound",
+		"ObjectLockConfigurationNotFoundError", "NoSuchObjectLockConfiguration":
+		return http.StatusNotFound
+	case "AccessDenied":
+		// Também retornado quando a retenção ou o legal hold do Object Lock bloqueia a operação
+		return http.StatusForbidden
+	case "BucketAlreadyExists", "BucketAlreadyOwnedByYou", "BucketNotEmpty", "InvalidBucketState":
+		return http.StatusConflict
+	case "PreconditionFailed":
+		return http.StatusPreconditionFailed
+	case "BadDigest", "InvalidDigest":
+		return http.StatusUnprocessableEntity
+	case "InvalidBucketName", "InvalidLocationConstraint", "IllegalLocationConstraintException", "InvalidArgument", "InvalidRequest", "MalformedXML":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// sqsErrorStatus converte erros do SQS no status HTTP equivalente
+func sqsErrorStatus(err error) int {
+	switch apiErrorCode(err) {
+	case "QueueDoesNotExist", "AWS.SimpleQueueService.NonExistentQueue":
+		return http.StatusNotFound
+	case "QueueNameExists", "QueueAlreadyExists", "QueueDeletedRecently", "AWS.SimpleQueueService.QueueDeletedRecently":
+		return http.StatusConflict
+	case "InvalidAttributeName", "InvalidAttributeValue", "InvalidParameterValue", "MissingParameter", "ValidationError",
+		"ReceiptHandleIsInvalid", "MessageNotInflight", "AWS.SimpleQueueService.MessageNotInflight":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
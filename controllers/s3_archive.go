@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+)
+
+// Objetos buscados em paralelo e tamanho máximo lido antecipadamente para a memória;
+// objetos maiores são transmitidos direto do S3 quando chega a vez deles
+const (
+	archiveConcurrency   = 4
+	archivePrefetchLimit = 8 * 1024 * 1024
+)
+
+type archiveEntry struct {
+	Key      string
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+type archiveFetch struct {
+	entry archiveEntry
+	body  io.ReadCloser
+	err   error
+}
+
+// archiveEntryName gera o nome do arquivo dentro do ZIP relativo ao prefixo, sem permitir "../".
+// O prefixo é tratado como diretório, para que "docs" não corte o início de chaves como "docsX/a"
+func archiveEntryName(key, prefix string) string {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(key, prefix)), "/")
+	if name == "" {
+		name = path.Base(key)
+	}
+	return name
+}
+
+// uniqueArchiveName adiciona um sufixo numérico quando chaves diferentes geram o mesmo nome (ex.: "a//b" e "a/b")
+func uniqueArchiveName(name string, used map[string]bool) string {
+	unique := name
+	for attempt := 1; used[unique]; attempt++ {
+		unique = renamedKey(name, attempt)
+	}
+	used[unique] = true
+	return unique
+}
+
+func (s *S3Controller) listArchiveEntries(ctx context.Context, bucket, prefix string) ([]archiveEntry, error) {
+	entries := make([]archiveEntry, 0)
+	used := make(map[string]bool)
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			// Marcadores de "pasta" não viram arquivos no ZIP
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			entries = append(entries, archiveEntry{
+				Key:      key,
+				Name:     uniqueArchiveName(archiveEntryName(key, prefix), used),
+				Size:     aws.ToInt64(object.Size),
+				Modified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return entries, nil
+}
+
+func (s *S3Controller) fetchArchiveEntry(ctx context.Context, bucket string, entry archiveEntry) (io.ReadCloser, error) {
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(entry.Key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry.Size > archivePrefetchLimit {
+		return result.Body, nil
+	}
+
+	defer result.Body.Close()
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// fetchArchiveEntries busca os objetos em paralelo e os entrega na ordem da listagem; no máximo
+// archiveConcurrency objetos ficam em andamento ou aguardando escrita ao mesmo tempo
+func (s *S3Controller) fetchArchiveEntries(ctx context.Context, bucket string, entries []archiveEntry) <-chan chan archiveFetch {
+	// Um objeto fica com quem escreve o ZIP e os demais aguardam no buffer
+	ordered := make(chan chan archiveFetch, archiveConcurrency-1)
+	go func() {
+		defer close(ordered)
+		for _, entry := range entries {
+			result := make(chan archiveFetch, 1)
+			select {
+			case ordered <- result:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(entry archiveEntry) {
+				body, err := s.fetchArchiveEntry(ctx, bucket, entry)
+				result <- archiveFetch{entry: entry, body: body, err: err}
+			}(entry)
+		}
+	}()
+	return ordered
+}
+
+func (s *S3Controller) DownloadArchive(c *gin.Context) {
+	bucket, err := s.resolveBucket(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	prefix := c.Query("prefix")
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	entries, err := s.listArchiveEntries(ctx, bucket, prefix)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao listar objetos: %v", err)})
+		return
+	}
+	if len(entries) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nenhum objeto encontrado no prefixo"})
+		return
+	}
+
+	name := path.Base(strings.TrimSuffix(prefix, "/"))
+	if name == "." || name == "/" || name == "" {
+		name = bucket
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", contentDisposition(name+".zip"))
+	c.Status(http.StatusOK)
+
+	ordered := s.fetchArchiveEntries(ctx, bucket, entries)
+	// Em caso de erro, cancelar as buscas restantes e liberar os corpos já abertos
+	abort := func() {
+		cancel()
+		for pending := range ordered {
+			if fetched := <-pending; fetched.body != nil {
+				fetched.body.Close()
+			}
+		}
+	}
+
+	// A partir daqui o status já foi enviado; erros apenas interrompem o ZIP
+	archive := zip.NewWriter(c.Writer)
+	for pending := range ordered {
+		fetched := <-pending
+		if fetched.err != nil {
+			log.Printf("Erro ao buscar %s para o ZIP: %v", fetched.entry.Key, fetched.err)
+			abort()
+			return
+		}
+
+		writer, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     fetched.entry.Name,
+			Method:   zip.Deflate,
+			Modified: fetched.entry.Modified,
+		})
+		if err == nil {
+			_, err = io.Copy(writer, fetched.body)
+		}
+		fetched.body.Close()
+		if err != nil {
+			log.Printf("Erro ao escrever %s no ZIP: %v", fetched.entry.Key, err)
+			abort()
+			return
+		}
+		c.Writer.Flush()
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Printf("Erro ao finalizar ZIP: %v", err)
+	}
+}
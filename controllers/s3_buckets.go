@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateBucketRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Region string `json:"region"`
+	// Aplica a política CORS padrão ao novo bucket
+	CORS bool `json:"cors"`
+	// Habilita Object Lock (e versionamento) no novo bucket
+	ObjectLock bool `json:"object_lock"`
+}
+
+func (s *S3Controller) CreateBucket(c *gin.Context) {
+	var req CreateBucketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome do bucket é obrigatório"})
+		return
+	}
+
+	region := req.Region
+	if region == "" {
+		region = s.region
+	}
+
+	if err := s.createBucket(context.TODO(), req.Name, region, req.ObjectLock); err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao criar bucket: %v", err)})
+		return
+	}
+
+	if req.CORS {
+		if err := s.putBucketCORS(context.TODO(), req.Name, defaultCORSConfiguration(s.corsAllowedOrigins)); err != nil {
+			c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Bucket criado, mas erro ao configurar CORS: %v", err)})
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Bucket criado com sucesso",
+		"bucket":      req.Name,
+		"region":      region,
+		"object_lock": req.ObjectLock,
+	})
+}
+
+func (s *S3Controller) ListBuckets(c *gin.Context) {
+	result, err := s.client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao listar buckets: %v", err)})
+		return
+	}
+
+	buckets := make([]gin.H, 0)
+	for _, bucket := range result.Buckets {
+		buckets = append(buckets, gin.H{
+			"name":          aws.ToString(bucket.Name),
+			"creation_date": aws.ToTime(bucket.CreationDate),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"buckets": buckets,
+	})
+}
+
+func (s *S3Controller) HeadBucket(c *gin.Context) {
+	result, err := s.client.HeadBucket(context.TODO(), &s3.HeadBucketInput{
+		Bucket: aws.String(c.Param("bucket")),
+	})
+	if err != nil {
+		c.Status(s3ErrorStatus(err))
+		return
+	}
+
+	if result.BucketRegion != nil {
+		c.Header("X-Amz-Bucket-Region", *result.BucketRegion)
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *S3Controller) DeleteBucket(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	// Esvaziar o bucket antes de deletar quando force=true
+	if c.Query("force") == "true" {
+		if err := s.emptyBucket(context.TODO(), bucket, bypassGovernanceParam(c)); err != nil {
+			c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao esvaziar bucket: %v", err)})
+			return
+		}
+	}
+
+	_, err := s.client.DeleteBucket(context.TODO(), &s3.DeleteBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao deletar bucket: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Bucket %s deletado com sucesso", bucket),
+	})
+}
+
+// emptyBucket remove todos os objetos, versões e marcadores de exclusão do bucket
+func (s *S3Controller) emptyBucket(ctx context.Context, bucket string, bypassGovernance *bool) error {
+	paginator := s3.NewListObjectVersionsPaginator(s.client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		objects := make([]types.ObjectIdentifier, 0, len(page.Versions)+len(page.DeleteMarkers))
+		for _, version := range page.Versions {
+			objects = append(objects, types.ObjectIdentifier{Key: version.Key, VersionId: version.VersionId})
+		}
+		for _, marker := range page.DeleteMarkers {
+			objects = append(objects, types.ObjectIdentifier{Key: marker.Key, VersionId: marker.VersionId})
+		}
+		if len(objects) == 0 {
+			continue
+		}
+
+		result, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+			BypassGovernanceRetention: bypassGovernance,
+		})
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			// Manter o código do S3 para que versões protegidas por Object Lock resultem em 403
+			return &smithy.GenericAPIError{
+				Code:    aws.ToString(result.Errors[0].Code),
+				Message: fmt.Sprintf("falha ao deletar %s: %s", aws.ToString(result.Errors[0].Key), aws.ToString(result.Errors[0].Message)),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errChecksumMismatch indica que o arquivo recebido não corresponde ao checksum informado pelo cliente
+var errChecksumMismatch = errors.New("checksum não confere com o arquivo recebido")
+
+type fileChecksums struct {
+	SHA256 []byte
+	CRC32C []byte
+	MD5    []byte
+}
+
+// computeChecksums calcula SHA-256, CRC32C e MD5 em uma única leitura e volta ao início do arquivo.
+// O arquivo é lido duas vezes no upload (aqui e no envio ao S3): o checksum precisa ser conhecido antes
+// do PutObject para compor a chave (estratégia hash), os metadados e o header x-amz-checksum-sha256,
+// e para recusar com 422 um arquivo divergente antes de gravá-lo
+func computeChecksums(src io.ReadSeeker) (fileChecksums, error) {
+	sha := sha256.New()
+	crc := crc32.New(crc32cTable)
+	sum := md5.New()
+	if _, err := io.Copy(io.MultiWriter(sha, crc, sum), src); err != nil {
+		return fileChecksums{}, err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return fileChecksums{}, err
+	}
+
+	crcBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(crcBytes, crc.Sum32())
+
+	return fileChecksums{
+		SHA256: sha.Sum(nil),
+		CRC32C: crcBytes,
+		MD5:    sum.Sum(nil),
+	}, nil
+}
+
+// withPayloadHash assina a requisição com o SHA-256 já calculado; sem ele o SDK leria o arquivo
+// mais uma vez só para montar a assinatura (x-amz-content-sha256)
+func withPayloadHash(checksums fileChecksums) func(*s3.Options) {
+	payloadHash := checksums.SHA256Hex()
+	return s3.WithAPIOptions(func(stack *middleware.Stack) error {
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("KnownPayloadHash",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				return next.HandleFinalize(v4.SetPayloadHash(ctx, payloadHash), in)
+			}), "ComputePayloadHash", middleware.Before)
+	})
+}
+
+func (f fileChecksums) SHA256Hex() string {
+	return hex.EncodeToString(f.SHA256)
+}
+
+// SHA256Base64 é o formato esperado pelo S3 em x-amz-checksum-sha256
+func (f fileChecksums) SHA256Base64() string {
+	return base64.StdEncoding.EncodeToString(f.SHA256)
+}
+
+func (f fileChecksums) CRC32CHex() string {
+	return hex.EncodeToString(f.CRC32C)
+}
+
+// decodeChecksum aceita o valor em hexadecimal ou base64
+func decodeChecksum(value string) ([]byte, error) {
+	if decoded, err := hex.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("checksum em formato inválido: %s", value)
+}
+
+// verifyExpectedChecksum compara o arquivo com o Content-MD5 (base64) e/ou o header X-Checksum,
+// que aceita "sha256:<valor>", "crc32c:<valor>" ou apenas o SHA-256
+func verifyExpectedChecksum(checksums fileChecksums, contentMD5, xChecksum string) error {
+	if contentMD5 != "" {
+		expected, err := base64.StdEncoding.DecodeString(contentMD5)
+		if err != nil {
+			return fmt.Errorf("header Content-MD5 em formato inválido")
+		}
+		if !bytes.Equal(expected, checksums.MD5) {
+			return fmt.Errorf("Content-MD5: %w", errChecksumMismatch)
+		}
+	}
+
+	if xChecksum != "" {
+		algorithm, value, found := strings.Cut(xChecksum, ":")
+		if !found {
+			algorithm, value = "sha256", xChecksum
+		}
+
+		expected, err := decodeChecksum(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+
+		var actual []byte
+		switch strings.ToLower(strings.TrimSpace(algorithm)) {
+		case "sha256":
+			actual = checksums.SHA256
+		case "crc32c":
+			actual = checksums.CRC32C
+		default:
+			return fmt.Errorf("algoritmo de checksum não suportado: %s", algorithm)
+		}
+		if !bytes.Equal(expected, actual) {
+			return fmt.Errorf("%s: %w", algorithm, errChecksumMismatch)
+		}
+	}
+	return nil
+}
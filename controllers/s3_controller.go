@@ -2,54 +2,132 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"path"
+	"strings"
+
+	"localstackdemo/config"
+	"localstackdemo/s3sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/gin-gonic/gin"
 )
 
 type S3Controller struct {
-	client *s3.Client
+	client              *s3.Client
+	defaultBucket       string
+	region              string
+	allowedContentTypes []string
+	keyStrategy         string
+	overwritePolicy     string
+	kmsKeyID            string
+	syncer              *s3sync.Syncer
+	syncRoot            string
+	bootstrapCORS       bool
+	corsAllowedOrigins  []string
+	thumbnails          *ThumbnailGenerator
 }
 
-func NewS3Controller(cfg aws.Config) *S3Controller {
+// lifetime é cancelado no encerramento do servidor e para a geração de miniaturas; veja Shutdown
+func NewS3Controller(lifetime context.Context, cfg aws.Config, s3Cfg config.S3Config) *S3Controller {
 	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
 		o.UsePathStyle = true
 	})
-	return &S3Controller{client: client}
+	return &S3Controller{
+		client:              client,
+		defaultBucket:       s3Cfg.DefaultBucket,
+		region:              s3Cfg.Region,
+		allowedContentTypes: s3Cfg.AllowedContentTypes,
+		keyStrategy:         s3Cfg.KeyStrategy,
+		overwritePolicy:     s3Cfg.OverwritePolicy,
+		kmsKeyID:            s3Cfg.KMSKeyID,
+		syncer:              s3sync.New(client),
+		syncRoot:            s3Cfg.SyncRoot,
+		bootstrapCORS:       s3Cfg.BootstrapCORS,
+		corsAllowedOrigins:  s3Cfg.CORSAllowedOrigins,
+		thumbnails:          NewThumbnailGenerator(lifetime, client, s3Cfg.ThumbnailSizes),
+	}
+}
+
+// Shutdown aguarda as tarefas em segundo plano do controller até o prazo de ctx
+func (s *S3Controller) Shutdown(ctx context.Context) error {
+	return s.thumbnails.Shutdown(ctx)
 }
 
 func (s *S3Controller) setupBucket() error {
-	_, err := s.client.CreateBucket(context.TODO(), &s3.CreateBucketInput{
-		Bucket: aws.String("demo-bucket"),
-		CreateBucketConfiguration: &types.CreateBucketConfiguration{
-			LocationConstraint: types.BucketLocationConstraintSaEast1,
-		},
-	})
+	err := s.createBucket(context.TODO(), s.defaultBucket, s.region, false)
 	if err != nil {
 		if !isBucketAlreadyExistsError(err) {
 			return fmt.Errorf("erro ao criar bucket S3: %v", err)
 		}
+		return nil
+	}
+
+	// Aplicar a política CORS padrão apenas quando o bucket acabou de ser criado
+	if s.bootstrapCORS {
+		if err := s.putBucketCORS(context.TODO(), s.defaultBucket, defaultCORSConfiguration(s.corsAllowedOrigins)); err != nil {
+			return fmt.Errorf("erro ao configurar CORS do bucket: %v", err)
+		}
 	}
 	return nil
 }
 
+func (s *S3Controller) createBucket(ctx context.Context, bucket, region string, objectLock bool) error {
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(bucket),
+	}
+	// Object Lock só pode ser habilitado na criação e ativa o versionamento automaticamente
+	if objectLock {
+		input.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
+	// us-east-1 não aceita LocationConstraint
+	if region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+
+	_, err := s.client.CreateBucket(ctx, input, func(o *s3.Options) {
+		o.Region = region
+	})
+	return err
+}
+
 func isBucketAlreadyExistsError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if err.Error() == "BucketAlreadyOwnedByYou" {
-		return true
+	var owned *types.BucketAlreadyOwnedByYou
+	return errors.As(err, &owned)
+}
+
+// resolveBucket retorna o bucket da rota ou, na ausência dele, o bucket padrão
+func (s *S3Controller) resolveBucket(c *gin.Context) (string, error) {
+	bucket := c.Param("bucket")
+	if bucket != "" {
+		return bucket, nil
+	}
+
+	// Configurar bucket padrão na primeira chamada
+	if err := s.setupBucket(); err != nil {
+		return "", err
 	}
-	return false
+	return s.defaultBucket, nil
+}
+
+func objectKeyParam(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("key"), "/")
 }
 
 func (s *S3Controller) UploadFile(c *gin.Context) {
-	// Configurar bucket na primeira chamada
-	if err := s.setupBucket(); err != nil {
+	bucket, err := s.resolveBucket(c)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -60,6 +138,34 @@ func (s *S3Controller) UploadFile(c *gin.Context) {
 		return
 	}
 
+	tags := c.PostFormMap("tags")
+	if err := validateTags(tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	metadata := c.PostFormMap("metadata")
+
+	// Estratégia de chave e política de sobrescrita podem ser definidas por upload
+	keyStrategy := c.DefaultPostForm("key_strategy", s.keyStrategy)
+	if !isValidKeyStrategy(keyStrategy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Estratégia de chave inválida: %s", keyStrategy)})
+		return
+	}
+	overwritePolicy := c.DefaultPostForm("overwrite", s.overwritePolicy)
+	if !isValidOverwritePolicy(overwritePolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Política de sobrescrita inválida: %s", overwritePolicy)})
+		return
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+	}
+	encryption, err := s.applyUploadEncryption(c, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	src, err := file.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao abrir arquivo"})
@@ -67,17 +173,183 @@ func (s *S3Controller) UploadFile(c *gin.Context) {
 	}
 	defer src.Close()
 
-	_, err = s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket: aws.String("demo-bucket"),
-		Key:    aws.String(file.Filename),
-		Body:   src,
+	// Detectar o tipo do conteúdo pelo cabeçalho do arquivo
+	mtype, err := mimetype.DetectReader(src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+	if !s.isContentTypeAllowed(mtype) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": fmt.Sprintf("Tipo de arquivo não permitido: %s", mtype.String())})
+		return
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+
+	// Calcular os checksums e validar contra o valor esperado pelo cliente
+	checksums, err := computeChecksums(src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+	if err := verifyExpectedChecksum(checksums, c.GetHeader("Content-MD5"), c.GetHeader("X-Checksum")); err != nil {
+		if errors.Is(err, errChecksumMismatch) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata["checksum-sha256"] = checksums.SHA256Hex()
+	metadata["checksum-crc32c"] = checksums.CRC32CHex()
+
+	input.Key = aws.String(buildObjectKey(keyStrategy, file.Filename, checksums.SHA256Hex()))
+	input.Body = src
+	input.ContentType = aws.String(mtype.String())
+	input.Metadata = metadata
+	input.Tagging = encodeTagging(tags)
+	// O S3 recalcula o SHA-256 e rejeita o upload se o conteúdo chegar corrompido
+	input.ChecksumAlgorithm = types.ChecksumAlgorithmSha256
+	input.ChecksumSHA256 = aws.String(checksums.SHA256Base64())
+
+	key, err := s.putObjectWithPolicy(context.TODO(), input, overwritePolicy, withPayloadHash(checksums))
+	if err != nil {
+		if isPreconditionFailedError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Objeto %s já existe", key)})
+			return
+		}
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao fazer upload: %v", err)})
+		return
+	}
+
+	response := gin.H{
+		"message":      fmt.Sprintf("Arquivo %s enviado com sucesso", key),
+		"bucket":       bucket,
+		"key":          key,
+		"content_type": mtype.String(),
+		"metadata":     metadata,
+		"tags":         tags,
+		"checksum": gin.H{
+			"sha256": checksums.SHA256Hex(),
+			"crc32c": checksums.CRC32CHex(),
+		},
+		"encryption": encryption,
+	}
+
+	// Miniaturas são geradas em segundo plano; objetos com SSE-C não podem ser relidos sem a chave do cliente
+	generateThumbnails := c.DefaultPostForm("thumbnails", "true") == "true"
+	if generateThumbnails && input.SSECustomerKey == nil && s.thumbnails.Supports(key, mtype.String()) {
+		job := s.thumbnails.Enqueue(bucket, key, mtype.String())
+		response["thumbnails"] = gin.H{"status": job.Status}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *S3Controller) ListObjects(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	objects := make([]gin.H, 0)
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(c.Query("prefix")),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao listar objetos: %v", err)})
+			return
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, gin.H{
+				"key":           aws.ToString(object.Key),
+				"size":          aws.ToInt64(object.Size),
+				"etag":          aws.ToString(object.ETag),
+				"last_modified": aws.ToTime(object.LastModified),
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket":  bucket,
+		"objects": objects,
+	})
+}
+
+func (s *S3Controller) DownloadFile(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIDParam(c),
+	}
+	// Objetos com SSE-C só podem ser lidos com a mesma chave usada no upload
+	customer, err := customerKeyFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if customer != nil {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = customer.Key
+		input.SSECustomerKeyMD5 = customer.MD5
+	}
+
+	result, err := s.client.GetObject(context.TODO(), input)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao baixar objeto: %v", err)})
+		return
+	}
+	defer result.Body.Close()
+
+	contentType := aws.ToString(result.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.DataFromReader(http.StatusOK, aws.ToInt64(result.ContentLength), contentType, result.Body, map[string]string{
+		"Content-Disposition":          contentDisposition(path.Base(key)),
+		"ETag":                         aws.ToString(result.ETag),
+		"X-Amz-Version-Id":             aws.ToString(result.VersionId),
+		"X-Amz-Server-Side-Encryption": string(result.ServerSideEncryption),
+	})
+}
+
+func (s *S3Controller) DeleteFile(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	// Com version_id a versão é removida definitivamente; sem ele, buckets versionados recebem um delete marker.
+	// Versões sob retenção ou legal hold retornam 403
+	result, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(key),
+		VersionId:                 versionIDParam(c),
+		BypassGovernanceRetention: bypassGovernanceParam(c),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao fazer upload: %v", err)})
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao deletar objeto: %v", err)})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("Arquivo %s enviado com sucesso", file.Filename),
+		"message":       fmt.Sprintf("Objeto %s deletado com sucesso", key),
+		"version_id":    aws.ToString(result.VersionId),
+		"delete_marker": aws.ToBool(result.DeleteMarker),
 	})
 }
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Acima de 5 GB o CopyObject não é aceito e a cópia é feita em partes
+const (
+	maxSingleCopySize = 5 * 1024 * 1024 * 1024
+	copyPartSize      = 512 * 1024 * 1024
+)
+
+type CopyObjectRequest struct {
+	SourceBucket      string `json:"source_bucket"`
+	SourceKey         string `json:"source_key" binding:"required"`
+	SourceVersionID   string `json:"source_version_id"`
+	DestinationBucket string `json:"destination_bucket"`
+	DestinationKey    string `json:"destination_key" binding:"required"`
+	// COPY preserva os metadados da origem; REPLACE usa metadata e content_type
+	MetadataDirective string            `json:"metadata_directive" binding:"omitempty,oneof=COPY REPLACE"`
+	Metadata          map[string]string `json:"metadata"`
+	ContentType       string            `json:"content_type"`
+}
+
+type copyResult struct {
+	ETag      string
+	VersionID string
+	Multipart bool
+}
+
+// bindCopyRequest valida a requisição; em movimentações a origem nunca pode ser igual ao destino,
+// senão a remoção da origem apagaria a única cópia
+func (s *S3Controller) bindCopyRequest(c *gin.Context, move bool) (*CopyObjectRequest, bool) {
+	var req CopyObjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source_key e destination_key são obrigatórios"})
+		return nil, false
+	}
+
+	// Buckets omitidos usam o bucket padrão
+	if req.SourceBucket == "" || req.DestinationBucket == "" {
+		if err := s.setupBucket(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+	}
+	if req.SourceBucket == "" {
+		req.SourceBucket = s.defaultBucket
+	}
+	if req.DestinationBucket == "" {
+		req.DestinationBucket = s.defaultBucket
+	}
+	if req.MetadataDirective == "" {
+		req.MetadataDirective = string(types.MetadataDirectiveCopy)
+	}
+
+	sameObject := req.SourceBucket == req.DestinationBucket && req.SourceKey == req.DestinationKey
+	if sameObject && (move || req.MetadataDirective == string(types.MetadataDirectiveCopy)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Origem e destino são iguais"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// copyObject copia o objeto de origem, usando UploadPartCopy quando ele excede o limite do CopyObject
+func (s *S3Controller) copyObject(ctx context.Context, req *CopyObjectRequest, source *s3.HeadObjectOutput) (*copyResult, error) {
+	if aws.ToInt64(source.ContentLength) > maxSingleCopySize {
+		return s.multipartCopyObject(ctx, req, source)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(req.DestinationBucket),
+		Key:               aws.String(req.DestinationKey),
+		CopySource:        aws.String(copySource(req.SourceBucket, req.SourceKey, req.SourceVersionID)),
+		CopySourceIfMatch: source.ETag,
+		MetadataDirective: types.MetadataDirective(req.MetadataDirective),
+		// Mantém a criptografia da origem em vez da padrão do bucket de destino, como na cópia em partes
+		ServerSideEncryption: source.ServerSideEncryption,
+		SSEKMSKeyId:          source.SSEKMSKeyId,
+		BucketKeyEnabled:     source.BucketKeyEnabled,
+	}
+	if req.MetadataDirective == string(types.MetadataDirectiveReplace) {
+		input.Metadata = req.Metadata
+		input.ContentType = aws.String(req.ContentType)
+		if req.ContentType == "" {
+			input.ContentType = source.ContentType
+		}
+	}
+
+	result, err := s.client.CopyObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	etag := ""
+	if result.CopyObjectResult != nil {
+		etag = aws.ToString(result.CopyObjectResult.ETag)
+	}
+	return &copyResult{ETag: etag, VersionID: aws.ToString(result.VersionId)}, nil
+}
+
+func (s *S3Controller) multipartCopyObject(ctx context.Context, req *CopyObjectRequest, source *s3.HeadObjectOutput) (*copyResult, error) {
+	// O upload multipart não herda tags nem metadados, então eles são copiados da origem
+	// (os metadados só quando a diretiva é COPY), assim como a criptografia
+	tagging, err := s.sourceTagging(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(req.DestinationBucket),
+		Key:                  aws.String(req.DestinationKey),
+		Metadata:             source.Metadata,
+		ContentType:          source.ContentType,
+		Tagging:              tagging,
+		ServerSideEncryption: source.ServerSideEncryption,
+		SSEKMSKeyId:          source.SSEKMSKeyId,
+		BucketKeyEnabled:     source.BucketKeyEnabled,
+	}
+	if req.MetadataDirective == string(types.MetadataDirectiveReplace) {
+		createInput.Metadata = req.Metadata
+		if req.ContentType != "" {
+			createInput.ContentType = aws.String(req.ContentType)
+		}
+	}
+
+	upload, err := s.client.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return nil, err
+	}
+
+	abort := func() {
+		s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(req.DestinationBucket),
+			Key:      aws.String(req.DestinationKey),
+			UploadId: upload.UploadId,
+		})
+	}
+
+	size := aws.ToInt64(source.ContentLength)
+	parts := make([]types.CompletedPart, 0, size/copyPartSize+1)
+	for start, partNumber := int64(0), int32(1); start < size; start, partNumber = start+copyPartSize, partNumber+1 {
+		end := min(start+copyPartSize, size) - 1
+
+		part, err := s.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:            aws.String(req.DestinationBucket),
+			Key:               aws.String(req.DestinationKey),
+			UploadId:          upload.UploadId,
+			PartNumber:        aws.Int32(partNumber),
+			CopySource:        aws.String(copySource(req.SourceBucket, req.SourceKey, req.SourceVersionID)),
+			CopySourceIfMatch: source.ETag,
+			CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		})
+		if err != nil {
+			abort()
+			return nil, err
+		}
+		parts = append(parts, types.CompletedPart{
+			ETag:       part.CopyPartResult.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+	}
+
+	result, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(req.DestinationBucket),
+		Key:             aws.String(req.DestinationKey),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		abort()
+		return nil, err
+	}
+
+	return &copyResult{
+		ETag:      aws.ToString(result.ETag),
+		VersionID: aws.ToString(result.VersionId),
+		Multipart: true,
+	}, nil
+}
+
+// sourceTagging retorna as tags do objeto de origem no formato do header x-amz-tagging
+func (s *S3Controller) sourceTagging(ctx context.Context, req *CopyObjectRequest) (*string, error) {
+	input := &s3.GetObjectTaggingInput{
+		Bucket: aws.String(req.SourceBucket),
+		Key:    aws.String(req.SourceKey),
+	}
+	if req.SourceVersionID != "" {
+		input.VersionId = aws.String(req.SourceVersionID)
+	}
+	result, err := s.client.GetObjectTagging(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar tags da origem: %v", err)
+	}
+	return encodeTagging(fromS3Tags(result.TagSet)), nil
+}
+
+// verifyCopy confere tamanho e, em cópias simples, o ETag do destino contra a origem
+func (s *S3Controller) verifyCopy(ctx context.Context, req *CopyObjectRequest, source *s3.HeadObjectOutput, copied *copyResult) error {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(req.DestinationBucket),
+		Key:    aws.String(req.DestinationKey),
+	}
+	if copied.VersionID != "" {
+		input.VersionId = aws.String(copied.VersionID)
+	}
+
+	destination, err := s.client.HeadObject(ctx, input)
+	if err != nil {
+		return err
+	}
+	if aws.ToInt64(destination.ContentLength) != aws.ToInt64(source.ContentLength) {
+		return fmt.Errorf("tamanho do destino (%d) difere da origem (%d)", aws.ToInt64(destination.ContentLength), aws.ToInt64(source.ContentLength))
+	}
+
+	// ETags de objetos multipart ou com SSE-KMS não são o MD5 do conteúdo e não são comparáveis
+	sourceETag := aws.ToString(source.ETag)
+	if !copied.Multipart && !strings.Contains(sourceETag, "-") && source.ServerSideEncryption != types.ServerSideEncryptionAwsKms {
+		if aws.ToString(destination.ETag) != sourceETag {
+			return fmt.Errorf("ETag do destino (%s) difere da origem (%s)", aws.ToString(destination.ETag), sourceETag)
+		}
+	}
+	return nil
+}
+
+func (s *S3Controller) headSource(ctx context.Context, req *CopyObjectRequest) (*s3.HeadObjectOutput, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(req.SourceBucket),
+		Key:    aws.String(req.SourceKey),
+	}
+	if req.SourceVersionID != "" {
+		input.VersionId = aws.String(req.SourceVersionID)
+	}
+	return s.client.HeadObject(ctx, input)
+}
+
+func (s *S3Controller) CopyFile(c *gin.Context) {
+	req, ok := s.bindCopyRequest(c, false)
+	if !ok {
+		return
+	}
+
+	source, err := s.headSource(context.TODO(), req)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar objeto de origem: %v", err)})
+		return
+	}
+
+	copied, err := s.copyObject(context.TODO(), req, source)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao copiar objeto: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Objeto copiado com sucesso",
+		"source":      gin.H{"bucket": req.SourceBucket, "key": req.SourceKey},
+		"destination": gin.H{"bucket": req.DestinationBucket, "key": req.DestinationKey, "version_id": copied.VersionID},
+		"etag":        copied.ETag,
+		"multipart":   copied.Multipart,
+	})
+}
+
+func (s *S3Controller) MoveFile(c *gin.Context) {
+	req, ok := s.bindCopyRequest(c, true)
+	if !ok {
+		return
+	}
+
+	source, err := s.headSource(context.TODO(), req)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar objeto de origem: %v", err)})
+		return
+	}
+
+	copied, err := s.copyObject(context.TODO(), req, source)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao copiar objeto: %v", err)})
+		return
+	}
+
+	// A origem só é removida depois que a cópia foi conferida
+	if err := s.verifyCopy(context.TODO(), req, source, copied); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Cópia não confere, origem mantida: %v", err)})
+		return
+	}
+
+	deleteInput := &s3.DeleteObjectInput{
+		Bucket: aws.String(req.SourceBucket),
+		Key:    aws.String(req.SourceKey),
+	}
+	if req.SourceVersionID != "" {
+		deleteInput.VersionId = aws.String(req.SourceVersionID)
+	}
+	if _, err := s.client.DeleteObject(context.TODO(), deleteInput); err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Objeto copiado, mas erro ao deletar origem: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Objeto movido com sucesso",
+		"source":      gin.H{"bucket": req.SourceBucket, "key": req.SourceKey},
+		"destination": gin.H{"bucket": req.DestinationBucket, "key": req.DestinationKey, "version_id": copied.VersionID},
+		"etag":        copied.ETag,
+		"multipart":   copied.Multipart,
+	})
+}
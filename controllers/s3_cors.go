@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Limite de regras CORS por bucket definido pelo S3
+const maxCORSRules = 100
+
+var allowedCORSMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPut:    true,
+	http.MethodPost:   true,
+	http.MethodDelete: true,
+	http.MethodHead:   true,
+}
+
+type CORSRule struct {
+	ID             string   `json:"id,omitempty"`
+	AllowedOrigins []string `json:"allowed_origins"`
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+	ExposeHeaders  []string `json:"expose_headers,omitempty"`
+	MaxAgeSeconds  int32    `json:"max_age_seconds,omitempty"`
+}
+
+type CORSConfiguration struct {
+	Rules []CORSRule `json:"rules"`
+}
+
+func (c CORSConfiguration) Validate() error {
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("informe ao menos uma regra")
+	}
+	if len(c.Rules) > maxCORSRules {
+		return fmt.Errorf("máximo de %d regras por bucket", maxCORSRules)
+	}
+
+	for i, rule := range c.Rules {
+		if len(rule.AllowedOrigins) == 0 {
+			return fmt.Errorf("regra %d: allowed_origins é obrigatório", i)
+		}
+		for _, origin := range rule.AllowedOrigins {
+			// O S3 aceita no máximo um curinga por origem
+			if origin == "" || strings.Count(origin, "*") > 1 {
+				return fmt.Errorf("regra %d: origem inválida: %q", i, origin)
+			}
+		}
+		if len(rule.AllowedMethods) == 0 {
+			return fmt.Errorf("regra %d: allowed_methods é obrigatório", i)
+		}
+		for _, method := range rule.AllowedMethods {
+			if !allowedCORSMethods[method] {
+				return fmt.Errorf("regra %d: método não suportado: %s", i, method)
+			}
+		}
+		for _, header := range rule.AllowedHeaders {
+			if header == "" || strings.Count(header, "*") > 1 {
+				return fmt.Errorf("regra %d: header inválido: %q", i, header)
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			return fmt.Errorf("regra %d: max_age_seconds não pode ser negativo", i)
+		}
+	}
+	return nil
+}
+
+func (r CORSRule) toS3() types.CORSRule {
+	rule := types.CORSRule{
+		AllowedOrigins: r.AllowedOrigins,
+		AllowedMethods: r.AllowedMethods,
+		AllowedHeaders: r.AllowedHeaders,
+		ExposeHeaders:  r.ExposeHeaders,
+	}
+	if r.ID != "" {
+		rule.ID = aws.String(r.ID)
+	}
+	if r.MaxAgeSeconds > 0 {
+		rule.MaxAgeSeconds = aws.Int32(r.MaxAgeSeconds)
+	}
+	return rule
+}
+
+// defaultCORSConfiguration libera uploads e downloads diretos do navegador para as origens informadas
+func defaultCORSConfiguration(origins []string) CORSConfiguration {
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	return CORSConfiguration{
+		Rules: []CORSRule{
+			{
+				ID:             "default",
+				AllowedOrigins: origins,
+				AllowedMethods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodHead},
+				AllowedHeaders: []string{"*"},
+				ExposeHeaders:  []string{"ETag", "x-amz-version-id"},
+				MaxAgeSeconds:  3000,
+			},
+		},
+	}
+}
+
+func (s *S3Controller) putBucketCORS(ctx context.Context, bucket string, cors CORSConfiguration) error {
+	rules := make([]types.CORSRule, 0, len(cors.Rules))
+	for _, rule := range cors.Rules {
+		rules = append(rules, rule.toS3())
+	}
+
+	_, err := s.client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+		Bucket: aws.String(bucket),
+		CORSConfiguration: &types.CORSConfiguration{
+			CORSRules: rules,
+		},
+	})
+	return err
+}
+
+func (s *S3Controller) PutBucketCORS(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	var req CORSConfiguration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.putBucketCORS(context.TODO(), bucket, req); err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar CORS: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "CORS configurado com sucesso",
+		"bucket":  bucket,
+		"rules":   req.Rules,
+	})
+}
+
+func (s *S3Controller) GetBucketCORS(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	result, err := s.client.GetBucketCors(context.TODO(), &s3.GetBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar CORS: %v", err)})
+		return
+	}
+
+	rules := make([]CORSRule, 0, len(result.CORSRules))
+	for _, rule := range result.CORSRules {
+		rules = append(rules, CORSRule{
+			ID:             aws.ToString(rule.ID),
+			AllowedOrigins: rule.AllowedOrigins,
+			AllowedMethods: rule.AllowedMethods,
+			AllowedHeaders: rule.AllowedHeaders,
+			ExposeHeaders:  rule.ExposeHeaders,
+			MaxAgeSeconds:  aws.ToInt32(rule.MaxAgeSeconds),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"rules":  rules,
+	})
+}
+
+func (s *S3Controller) DeleteBucketCORS(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	_, err := s.client.DeleteBucketCors(context.TODO(), &s3.DeleteBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao remover CORS: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "CORS removido com sucesso",
+	})
+}
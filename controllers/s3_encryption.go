@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Modos de criptografia aceitos no upload
+const (
+	EncryptionSSES3  = "sse-s3"
+	EncryptionSSEKMS = "sse-kms"
+	EncryptionSSEC   = "sse-c"
+)
+
+// Header com a chave do cliente (base64, 256 bits) usada no SSE-C
+const customerKeyHeader = "X-Encryption-Key"
+
+type customerKey struct {
+	Key *string
+	MD5 *string
+}
+
+// customerKeyFromRequest lê a chave SSE-C do header; retorna nil quando ausente
+func customerKeyFromRequest(c *gin.Context) (*customerKey, error) {
+	encoded := c.GetHeader(customerKeyHeader)
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s deve conter uma chave de 256 bits em base64", customerKeyHeader)
+	}
+	sum := md5.Sum(key)
+	return &customerKey{
+		Key: aws.String(encoded),
+		MD5: aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+	}, nil
+}
+
+// applyUploadEncryption configura o PutObject conforme o campo encryption do formulário
+func (s *S3Controller) applyUploadEncryption(c *gin.Context, input *s3.PutObjectInput) (gin.H, error) {
+	switch mode := c.PostForm("encryption"); mode {
+	case "":
+		return nil, nil
+	case EncryptionSSES3:
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+		return gin.H{"mode": mode}, nil
+	case EncryptionSSEKMS:
+		keyID := c.DefaultPostForm("kms_key_id", s.kmsKeyID)
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		// Sem chave informada o S3 usa a chave gerenciada aws/s3
+		if keyID != "" {
+			input.SSEKMSKeyId = aws.String(keyID)
+		}
+		return gin.H{"mode": mode, "kms_key_id": keyID}, nil
+	case EncryptionSSEC:
+		key, err := customerKeyFromRequest(c)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			return nil, fmt.Errorf("SSE-C exige o header %s", customerKeyHeader)
+		}
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = key.Key
+		input.SSECustomerKeyMD5 = key.MD5
+		return gin.H{"mode": mode, "customer_key_md5": aws.ToString(key.MD5)}, nil
+	default:
+		return nil, fmt.Errorf("criptografia inválida: %s", mode)
+	}
+}
+
+type PutBucketEncryptionRequest struct {
+	Algorithm        string `json:"algorithm" binding:"required,oneof=AES256 aws:kms"`
+	KMSKeyID         string `json:"kms_key_id"`
+	BucketKeyEnabled bool   `json:"bucket_key_enabled"`
+}
+
+func (s *S3Controller) PutBucketEncryption(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	var req PutBucketEncryptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Algoritmo deve ser AES256 ou aws:kms"})
+		return
+	}
+
+	rule := types.ServerSideEncryptionByDefault{
+		SSEAlgorithm: types.ServerSideEncryption(req.Algorithm),
+	}
+	if req.Algorithm == string(types.ServerSideEncryptionAwsKms) {
+		if req.KMSKeyID == "" {
+			req.KMSKeyID = s.kmsKeyID
+		}
+		if req.KMSKeyID != "" {
+			rule.KMSMasterKeyID = aws.String(req.KMSKeyID)
+		}
+	}
+
+	_, err := s.client.PutBucketEncryption(context.TODO(), &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{
+				{
+					ApplyServerSideEncryptionByDefault: &rule,
+					BucketKeyEnabled:                   aws.Bool(req.BucketKeyEnabled),
+				},
+			},
+		},
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar criptografia: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Criptografia padrão configurada com sucesso",
+		"bucket":             bucket,
+		"algorithm":          req.Algorithm,
+		"kms_key_id":         req.KMSKeyID,
+		"bucket_key_enabled": req.BucketKeyEnabled,
+	})
+}
+
+func (s *S3Controller) GetBucketEncryption(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	result, err := s.client.GetBucketEncryption(context.TODO(), &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if apiErrorCode(err) == "ServerSideEncryptionConfigurationNotFoundError" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bucket sem criptografia padrão configurada"})
+			return
+		}
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar criptografia: %v", err)})
+		return
+	}
+
+	rules := make([]gin.H, 0)
+	if result.ServerSideEncryptionConfiguration != nil {
+		for _, rule := range result.ServerSideEncryptionConfiguration.Rules {
+			if rule.ApplyServerSideEncryptionByDefault == nil {
+				continue
+			}
+			rules = append(rules, gin.H{
+				"algorithm":          rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm,
+				"kms_key_id":         aws.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID),
+				"bucket_key_enabled": aws.ToBool(rule.BucketKeyEnabled),
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"rules":  rules,
+	})
+}
+
+func (s *S3Controller) DeleteBucketEncryption(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	_, err := s.client.DeleteBucketEncryption(context.TODO(), &s3.DeleteBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao remover criptografia: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Criptografia padrão removida com sucesso",
+	})
+}
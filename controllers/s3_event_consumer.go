@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Quantidade máxima de registros mantidos em memória
+const maxStoredS3Events = 500
+
+// S3EventConsumer lê as notificações do S3 entregues na fila de eventos e guarda os registros recebidos
+type S3EventConsumer struct {
+	client    *sqs.Client
+	queueName string
+	// Contexto de vida do servidor; o loop de leitura termina quando ele é cancelado
+	lifetime context.Context
+
+	queueMu  sync.Mutex
+	queueURL string
+
+	startOnce sync.Once
+
+	mu      sync.RWMutex
+	records []events.S3EventRecord
+}
+
+func NewS3EventConsumer(lifetime context.Context, client *sqs.Client, queueName string) *S3EventConsumer {
+	return &S3EventConsumer{
+		client:    client,
+		queueName: queueName,
+		lifetime:  lifetime,
+		records:   make([]events.S3EventRecord, 0),
+	}
+}
+
+// QueueURL cria a fila de eventos na primeira chamada e retorna sua URL
+func (e *S3EventConsumer) QueueURL(ctx context.Context) (string, error) {
+	e.queueMu.Lock()
+	defer e.queueMu.Unlock()
+	if e.queueURL != "" {
+		return e.queueURL, nil
+	}
+
+	output, err := e.client.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName: aws.String(e.queueName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar fila de eventos: %v", err)
+	}
+	e.queueURL = *output.QueueUrl
+	return e.queueURL, nil
+}
+
+// QueueARN retorna o ARN da fila de eventos, usado na configuração de notificação do bucket
+func (e *S3EventConsumer) QueueARN(ctx context.Context) (string, error) {
+	queueURL, err := e.QueueURL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := e.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameQueueArn},
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao consultar ARN da fila de eventos: %v", err)
+	}
+	return output.Attributes[string(sqstypes.QueueAttributeNameQueueArn)], nil
+}
+
+// Start inicia o loop de leitura em segundo plano apenas uma vez; ele é encerrado junto com o servidor
+func (e *S3EventConsumer) Start() {
+	e.startOnce.Do(func() {
+		go e.run(e.lifetime)
+	})
+}
+
+func (e *S3EventConsumer) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		queueURL, err := e.QueueURL(ctx)
+		if err != nil {
+			log.Printf("Consumidor de eventos S3: %v", err)
+			sleepContext(ctx, 5*time.Second)
+			continue
+		}
+
+		result, err := e.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20, // Long polling
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Erro ao receber eventos S3: %v", err)
+				sleepContext(ctx, 5*time.Second)
+			}
+			continue
+		}
+
+		for _, message := range result.Messages {
+			records, err := parseS3EventMessage(aws.ToString(message.Body))
+			if err != nil {
+				log.Printf("Mensagem de evento S3 ignorada: %v", err)
+			}
+			e.store(records)
+
+			// Mensagens inválidas também são removidas para não voltarem à fila indefinidamente
+			_, err = e.client.DeleteMessage(context.WithoutCancel(ctx), &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(queueURL),
+				ReceiptHandle: message.ReceiptHandle,
+			})
+			if err != nil {
+				log.Printf("Erro ao deletar evento S3: %v", err)
+			}
+		}
+	}
+}
+
+// snsEnvelope é o formato usado quando o evento chega à fila através de um tópico SNS
+type snsEnvelope struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// parseS3EventMessage converte o corpo da mensagem em registros tipados; eventos de teste retornam vazio
+func parseS3EventMessage(body string) ([]events.S3EventRecord, error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal([]byte(body), &envelope); err == nil && envelope.Type == "Notification" {
+		body = envelope.Message
+	}
+
+	var event events.S3Event
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		return nil, fmt.Errorf("corpo não é um evento S3: %v", err)
+	}
+	return event.Records, nil
+}
+
+func (e *S3EventConsumer) store(records []events.S3EventRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = append(e.records, records...)
+	if overflow := len(e.records) - maxStoredS3Events; overflow > 0 {
+		e.records = append([]events.S3EventRecord(nil), e.records[overflow:]...)
+	}
+}
+
+// Records retorna uma cópia dos registros recebidos
+func (e *S3EventConsumer) Records() []events.S3EventRecord {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	records := make([]events.S3EventRecord, len(e.records))
+	copy(records, e.records)
+	return records
+}
+
+func (e *S3EventConsumer) Clear() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = make([]events.S3EventRecord, 0)
+}
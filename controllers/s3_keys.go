@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/google/uuid"
+)
+
+// Estratégias de geração de chave
+const (
+	KeyStrategyOriginal = "original"
+	KeyStrategyUUID     = "uuid"
+	KeyStrategyDate     = "date"
+	KeyStrategyHash     = "hash"
+)
+
+// Políticas de sobrescrita
+const (
+	OverwriteAllow  = "allow"
+	OverwriteReject = "reject"
+	OverwriteRename = "rename"
+)
+
+// Número máximo de sufixos testados na política rename
+const maxRenameAttempts = 100
+
+func isValidKeyStrategy(strategy string) bool {
+	switch strategy {
+	case KeyStrategyOriginal, KeyStrategyUUID, KeyStrategyDate, KeyStrategyHash:
+		return true
+	}
+	return false
+}
+
+func isValidOverwritePolicy(policy string) bool {
+	switch policy {
+	case OverwriteAllow, OverwriteReject, OverwriteRename:
+		return true
+	}
+	return false
+}
+
+// sanitizeFilename remove diretórios e caracteres inseguros do nome enviado pelo cliente,
+// preservando letras acentuadas e de outros alfabetos
+func sanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+
+	sanitized := strings.TrimLeft(b.String(), ".")
+	if sanitized == "" {
+		return "arquivo"
+	}
+	return sanitized
+}
+
+// contentDisposition monta o cabeçalho de download com um nome ASCII para clientes antigos
+// e o nome original em UTF-8 (RFC 5987)
+func contentDisposition(filename string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			continue
+		case r == '"' || r == '\\':
+			fallback.WriteRune('_')
+		case r > unicode.MaxASCII:
+			fallback.WriteRune('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	for _, c := range []byte(filename) {
+		if isAttrChar(c) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback.String(), encoded.String())
+}
+
+// isAttrChar indica os caracteres que não precisam de percent-encoding em parâmetros estendidos (RFC 5987)
+func isAttrChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// buildObjectKey gera a chave do objeto conforme a estratégia; hash usa o SHA-256 do conteúdo
+func buildObjectKey(strategy, filename, sha256Hex string) string {
+	name := sanitizeFilename(filename)
+	ext := path.Ext(name)
+
+	switch strategy {
+	case KeyStrategyUUID:
+		return uuid.New().String() + ext
+	case KeyStrategyDate:
+		return time.Now().UTC().Format("2006/01/02") + "/" + name
+	case KeyStrategyHash:
+		return sha256Hex + ext
+	}
+	return name
+}
+
+// renamedKey adiciona um sufixo numérico antes da extensão (ex.: foto-1.jpg)
+func renamedKey(key string, attempt int) string {
+	ext := path.Ext(key)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(key, ext), attempt, ext)
+}
+
+// ifNoneMatch faz o PutObject falhar com PreconditionFailed se a chave já existir
+func ifNoneMatch() func(*s3.Options) {
+	return s3.WithAPIOptions(smithyhttp.SetHeaderValue("If-None-Match", "*"))
+}
+
+func isPreconditionFailedError(err error) bool {
+	return apiErrorCode(err) == "PreconditionFailed"
+}
+
+// putObjectWithPolicy envia o objeto respeitando a política de sobrescrita e retorna a chave final
+func (s *S3Controller) putObjectWithPolicy(ctx context.Context, input *s3.PutObjectInput, policy string, optFns ...func(*s3.Options)) (string, error) {
+	conditional := append(append([]func(*s3.Options){}, optFns...), ifNoneMatch())
+	switch policy {
+	case OverwriteReject:
+		_, err := s.client.PutObject(ctx, input, conditional...)
+		return aws.ToString(input.Key), err
+	case OverwriteRename:
+		body, seekable := input.Body.(io.ReadSeeker)
+		baseKey := aws.ToString(input.Key)
+		for attempt := 0; attempt < maxRenameAttempts; attempt++ {
+			key := baseKey
+			if attempt > 0 {
+				key = renamedKey(baseKey, attempt)
+				if !seekable {
+					return "", fmt.Errorf("não é possível reenviar o conteúdo para %s", key)
+				}
+				if _, err := body.Seek(0, io.SeekStart); err != nil {
+					return "", err
+				}
+			}
+
+			attemptInput := *input
+			attemptInput.Key = &key
+			_, err := s.client.PutObject(ctx, &attemptInput, conditional...)
+			if err == nil {
+				return key, nil
+			}
+			if !isPreconditionFailedError(err) {
+				return "", err
+			}
+		}
+		return "", fmt.Errorf("nenhuma chave livre encontrada para %s", baseKey)
+	}
+
+	_, err := s.client.PutObject(ctx, input, optFns...)
+	return aws.ToString(input.Key), err
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Limites de configuração de ciclo de vida definidos pelo S3
+const (
+	maxLifecycleRules    = 1000
+	maxLifecycleIDLength = 255
+)
+
+type LifecycleRule struct {
+	ID string `json:"id"`
+	// Enabled ou Disabled (padrão: Enabled)
+	Status string `json:"status,omitempty"`
+	// Filtro por prefixo e/ou tags; sem filtro a regra vale para o bucket inteiro
+	Prefix string            `json:"prefix,omitempty"`
+	Tags   map[string]string `json:"tags,omitempty"`
+	// Ações
+	ExpirationDays                     int32 `json:"expiration_days,omitempty"`
+	NoncurrentVersionExpirationDays    int32 `json:"noncurrent_version_expiration_days,omitempty"`
+	NewerNoncurrentVersions            int32 `json:"newer_noncurrent_versions,omitempty"`
+	AbortIncompleteMultipartUploadDays int32 `json:"abort_incomplete_multipart_upload_days,omitempty"`
+}
+
+type LifecycleConfiguration struct {
+	Rules []LifecycleRule `json:"rules"`
+}
+
+func (l LifecycleConfiguration) Validate() error {
+	if len(l.Rules) == 0 {
+		return fmt.Errorf("informe ao menos uma regra")
+	}
+	if len(l.Rules) > maxLifecycleRules {
+		return fmt.Errorf("máximo de %d regras por bucket", maxLifecycleRules)
+	}
+
+	ids := make(map[string]bool, len(l.Rules))
+	for i, rule := range l.Rules {
+		if rule.ID == "" || len(rule.ID) > maxLifecycleIDLength {
+			return fmt.Errorf("regra %d: id é obrigatório e deve ter até %d caracteres", i, maxLifecycleIDLength)
+		}
+		if ids[rule.ID] {
+			return fmt.Errorf("regra %s: id duplicado", rule.ID)
+		}
+		ids[rule.ID] = true
+
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("regra %s: %v", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r LifecycleRule) validate() error {
+	if r.Status != "" && r.Status != string(types.ExpirationStatusEnabled) && r.Status != string(types.ExpirationStatusDisabled) {
+		return fmt.Errorf("status deve ser Enabled ou Disabled")
+	}
+	if r.ExpirationDays < 0 || r.NoncurrentVersionExpirationDays < 0 || r.NewerNoncurrentVersions < 0 || r.AbortIncompleteMultipartUploadDays < 0 {
+		return fmt.Errorf("dias e quantidades não podem ser negativos")
+	}
+	if r.ExpirationDays == 0 && r.NoncurrentVersionExpirationDays == 0 && r.AbortIncompleteMultipartUploadDays == 0 {
+		return fmt.Errorf("informe ao menos uma ação (expiração, expiração de versões antigas ou abortar multipart)")
+	}
+	if r.NewerNoncurrentVersions > 0 && r.NoncurrentVersionExpirationDays == 0 {
+		return fmt.Errorf("newer_noncurrent_versions exige noncurrent_version_expiration_days")
+	}
+	// O S3 não aceita abortar uploads multipart em regras filtradas por tag
+	if r.AbortIncompleteMultipartUploadDays > 0 && len(r.Tags) > 0 {
+		return fmt.Errorf("abort_incomplete_multipart_upload_days não pode ser usado com filtro por tags")
+	}
+	for key, value := range r.Tags {
+		if key == "" || len(key) > maxTagKeyLength || len(value) > maxTagValueLength {
+			return fmt.Errorf("tag inválida: %q", key)
+		}
+	}
+	return nil
+}
+
+func (r LifecycleRule) filter() types.LifecycleRuleFilter {
+	switch {
+	case len(r.Tags) == 0:
+		return &types.LifecycleRuleFilterMemberPrefix{Value: r.Prefix}
+	case len(r.Tags) == 1 && r.Prefix == "":
+		tag := toS3Tags(r.Tags)[0]
+		return &types.LifecycleRuleFilterMemberTag{Value: tag}
+	}
+	return &types.LifecycleRuleFilterMemberAnd{
+		Value: types.LifecycleRuleAndOperator{
+			Prefix: aws.String(r.Prefix),
+			Tags:   toS3Tags(r.Tags),
+		},
+	}
+}
+
+func (r LifecycleRule) toS3() types.LifecycleRule {
+	status := types.ExpirationStatusEnabled
+	if r.Status != "" {
+		status = types.ExpirationStatus(r.Status)
+	}
+
+	rule := types.LifecycleRule{
+		ID:     aws.String(r.ID),
+		Status: status,
+		Filter: r.filter(),
+	}
+	if r.ExpirationDays > 0 {
+		rule.Expiration = &types.LifecycleExpiration{Days: aws.Int32(r.ExpirationDays)}
+	}
+	if r.NoncurrentVersionExpirationDays > 0 {
+		rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int32(r.NoncurrentVersionExpirationDays),
+		}
+		if r.NewerNoncurrentVersions > 0 {
+			rule.NoncurrentVersionExpiration.NewerNoncurrentVersions = aws.Int32(r.NewerNoncurrentVersions)
+		}
+	}
+	if r.AbortIncompleteMultipartUploadDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int32(r.AbortIncompleteMultipartUploadDays),
+		}
+	}
+	return rule
+}
+
+func lifecycleRuleFromS3(rule types.LifecycleRule) LifecycleRule {
+	r := LifecycleRule{
+		ID:     aws.ToString(rule.ID),
+		Status: string(rule.Status),
+		Prefix: aws.ToString(rule.Prefix),
+	}
+
+	switch filter := rule.Filter.(type) {
+	case *types.LifecycleRuleFilterMemberPrefix:
+		r.Prefix = filter.Value
+	case *types.LifecycleRuleFilterMemberTag:
+		r.Tags = fromS3Tags([]types.Tag{filter.Value})
+	case *types.LifecycleRuleFilterMemberAnd:
+		r.Prefix = aws.ToString(filter.Value.Prefix)
+		r.Tags = fromS3Tags(filter.Value.Tags)
+	}
+
+	if rule.Expiration != nil {
+		r.ExpirationDays = aws.ToInt32(rule.Expiration.Days)
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		r.NoncurrentVersionExpirationDays = aws.ToInt32(rule.NoncurrentVersionExpiration.NoncurrentDays)
+		r.NewerNoncurrentVersions = aws.ToInt32(rule.NoncurrentVersionExpiration.NewerNoncurrentVersions)
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		r.AbortIncompleteMultipartUploadDays = aws.ToInt32(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+	return r
+}
+
+func (s *S3Controller) PutBucketLifecycle(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	var req LifecycleConfiguration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules := make([]types.LifecycleRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules = append(rules, rule.toS3())
+	}
+
+	_, err := s.client.PutBucketLifecycleConfiguration(context.TODO(), &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+			Rules: rules,
+		},
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar ciclo de vida: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ciclo de vida configurado com sucesso",
+		"bucket":  bucket,
+		"rules":   req.Rules,
+	})
+}
+
+func (s *S3Controller) GetBucketLifecycle(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	result, err := s.client.GetBucketLifecycleConfiguration(context.TODO(), &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar ciclo de vida: %v", err)})
+		return
+	}
+
+	rules := make([]LifecycleRule, 0, len(result.Rules))
+	for _, rule := range result.Rules {
+		rules = append(rules, lifecycleRuleFromS3(rule))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"rules":  rules,
+	})
+}
+
+func (s *S3Controller) DeleteBucketLifecycle(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	_, err := s.client.DeleteBucketLifecycle(context.TODO(), &s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao remover ciclo de vida: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ciclo de vida removido com sucesso",
+	})
+}
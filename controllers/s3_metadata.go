@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+)
+
+// Limites de tags definidos pelo S3
+const (
+	maxObjectTags     = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// isContentTypeAllowed verifica o tipo detectado contra a allow-list (aceita curingas como image/*)
+func (s *S3Controller) isContentTypeAllowed(mtype *mimetype.MIME) bool {
+	if len(s.allowedContentTypes) == 0 {
+		return true
+	}
+
+	base := strings.TrimSpace(strings.Split(mtype.String(), ";")[0])
+	for _, allowed := range s.allowedContentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(base, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mtype.Is(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("máximo de %d tags por objeto", maxObjectTags)
+	}
+	for key, value := range tags {
+		if key == "" || len(key) > maxTagKeyLength {
+			return fmt.Errorf("chave de tag inválida: %q", key)
+		}
+		if len(value) > maxTagValueLength {
+			return fmt.Errorf("valor da tag %q excede %d caracteres", key, maxTagValueLength)
+		}
+	}
+	return nil
+}
+
+// encodeTagging converte as tags para o formato de query string usado no header x-amz-tagging
+func encodeTagging(tags map[string]string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	return aws.String(values.Encode())
+}
+
+func toS3Tags(tags map[string]string) []types.Tag {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]types.Tag, 0, len(keys))
+	for _, key := range keys {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return tagSet
+}
+
+func fromS3Tags(tagSet []types.Tag) map[string]string {
+	tags := make(map[string]string, len(tagSet))
+	for _, tag := range tagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags
+}
+
+func (s *S3Controller) HeadFile(c *gin.Context) {
+	key := objectKeyParam(c)
+	if key == "" {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	input := &s3.HeadObjectInput{
+		Bucket:    aws.String(c.Param("bucket")),
+		Key:       aws.String(key),
+		VersionId: versionIDParam(c),
+	}
+	customer, err := customerKeyFromRequest(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	if customer != nil {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = customer.Key
+		input.SSECustomerKeyMD5 = customer.MD5
+	}
+
+	result, err := s.client.HeadObject(context.TODO(), input)
+	if err != nil {
+		c.Status(s3ErrorStatus(err))
+		return
+	}
+
+	c.Header("Content-Type", aws.ToString(result.ContentType))
+	c.Header("Content-Length", fmt.Sprintf("%d", aws.ToInt64(result.ContentLength)))
+	c.Header("ETag", aws.ToString(result.ETag))
+	if result.VersionId != nil {
+		c.Header("X-Amz-Version-Id", *result.VersionId)
+	}
+	if result.ServerSideEncryption != "" {
+		c.Header("X-Amz-Server-Side-Encryption", string(result.ServerSideEncryption))
+	}
+	if result.SSEKMSKeyId != nil {
+		c.Header("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", *result.SSEKMSKeyId)
+	}
+	if result.SSECustomerAlgorithm != nil {
+		c.Header("X-Amz-Server-Side-Encryption-Customer-Algorithm", *result.SSECustomerAlgorithm)
+	}
+	if result.ObjectLockMode != "" {
+		c.Header("X-Amz-Object-Lock-Mode", string(result.ObjectLockMode))
+	}
+	if result.ObjectLockRetainUntilDate != nil {
+		c.Header("X-Amz-Object-Lock-Retain-Until-Date", result.ObjectLockRetainUntilDate.UTC().Format(time.RFC3339))
+	}
+	if result.ObjectLockLegalHoldStatus != "" {
+		c.Header("X-Amz-Object-Lock-Legal-Hold", string(result.ObjectLockLegalHoldStatus))
+	}
+	if result.LastModified != nil {
+		c.Header("Last-Modified", result.LastModified.UTC().Format(http.TimeFormat))
+	}
+	for name, value := range result.Metadata {
+		c.Header("X-Amz-Meta-"+name, value)
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *S3Controller) GetObjectTags(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	result, err := s.client.GetObjectTagging(context.TODO(), &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao buscar tags: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"key":    key,
+		"tags":   fromS3Tags(result.TagSet),
+	})
+}
+
+type PutObjectTagsRequest struct {
+	Tags map[string]string `json:"tags" binding:"required"`
+}
+
+func (s *S3Controller) PutObjectTags(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	var req PutObjectTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tags são obrigatórias"})
+		return
+	}
+	if err := validateTags(req.Tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err := s.client.PutObjectTagging(context.TODO(), &s3.PutObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Tagging: &types.Tagging{
+			TagSet: toS3Tags(req.Tags),
+		},
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao salvar tags: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tags atualizadas com sucesso",
+		"tags":    req.Tags,
+	})
+}
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"localstackdemo/config"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/gin-gonic/gin"
+)
+
+type S3NotificationController struct {
+	s3Client     *s3.Client
+	sqsClient    *sqs.Client
+	snsClient    *sns.Client
+	lambdaClient *lambda.Client
+	consumer     *S3EventConsumer
+	topicName    string
+}
+
+// lifetime é cancelado no encerramento do servidor e interrompe o consumidor da fila de eventos
+func NewS3NotificationController(lifetime context.Context, cfg aws.Config, s3Cfg config.S3Config) *S3NotificationController {
+	sqsClient := sqs.NewFromConfig(cfg)
+	return &S3NotificationController{
+		s3Client: s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.UsePathStyle = true
+		}),
+		sqsClient:    sqsClient,
+		snsClient:    sns.NewFromConfig(cfg),
+		lambdaClient: lambda.NewFromConfig(cfg),
+		consumer:     NewS3EventConsumer(lifetime, sqsClient, s3Cfg.EventsQueue),
+		topicName:    s3Cfg.EventsTopic,
+	}
+}
+
+type PutBucketNotificationsRequest struct {
+	// Tipos de evento (padrão: s3:ObjectCreated:* e s3:ObjectRemoved:*)
+	Events []string `json:"events"`
+	Prefix string   `json:"prefix"`
+	Suffix string   `json:"suffix"`
+	// Destinos: fila de eventos da aplicação, tópico da aplicação e/ou função Lambda
+	SQS            bool   `json:"sqs"`
+	SNS            bool   `json:"sns"`
+	LambdaFunction string `json:"lambda_function"`
+}
+
+var defaultNotificationEvents = []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+
+func notificationFilter(prefix, suffix string) *types.NotificationConfigurationFilter {
+	rules := make([]types.FilterRule, 0, 2)
+	if prefix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNamePrefix, Value: aws.String(prefix)})
+	}
+	if suffix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNameSuffix, Value: aws.String(suffix)})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &types.NotificationConfigurationFilter{
+		Key: &types.S3KeyFilter{FilterRules: rules},
+	}
+}
+
+func toS3Events(names []string) []types.Event {
+	s3Events := make([]types.Event, 0, len(names))
+	for _, name := range names {
+		s3Events = append(s3Events, types.Event(name))
+	}
+	return s3Events
+}
+
+// servicePolicy gera uma política que permite ao serviço informado executar a ação sobre o recurso
+func servicePolicy(action, resource string, services ...string) string {
+	principals := make([]string, 0, len(services))
+	for _, service := range services {
+		principals = append(principals, fmt.Sprintf("%q", service))
+	}
+	return fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":[%s]},"Action":%q,"Resource":%q}]}`,
+		strings.Join(principals, ","), action, resource)
+}
+
+// setupQueueTarget prepara a fila de eventos e retorna seu ARN
+func (n *S3NotificationController) setupQueueTarget(ctx context.Context) (string, error) {
+	queueARN, err := n.consumer.QueueARN(ctx)
+	if err != nil {
+		return "", err
+	}
+	queueURL, err := n.consumer.QueueURL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Permitir que S3 e SNS publiquem na fila
+	_, err = n.sqsClient.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL),
+		Attributes: map[string]string{
+			"Policy": servicePolicy("sqs:SendMessage", queueARN, "s3.amazonaws.com", "sns.amazonaws.com"),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao configurar política da fila: %v", err)
+	}
+	return queueARN, nil
+}
+
+// setupTopicTarget prepara o tópico da aplicação e inscreve nele a fila de eventos para que o consumidor também os receba
+func (n *S3NotificationController) setupTopicTarget(ctx context.Context) (string, error) {
+	createTopicOutput, err := n.snsClient.CreateTopic(ctx, &sns.CreateTopicInput{
+		Name: aws.String(n.topicName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar tópico SNS: %v", err)
+	}
+	topicARN := *createTopicOutput.TopicArn
+
+	_, err = n.snsClient.SetTopicAttributes(ctx, &sns.SetTopicAttributesInput{
+		TopicArn:       aws.String(topicARN),
+		AttributeName:  aws.String("Policy"),
+		AttributeValue: aws.String(servicePolicy("sns:Publish", topicARN, "s3.amazonaws.com")),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao configurar política do tópico: %v", err)
+	}
+
+	queueARN, err := n.setupQueueTarget(ctx)
+	if err != nil {
+		return "", err
+	}
+	_, err = n.snsClient.Subscribe(ctx, &sns.SubscribeInput{
+		TopicArn: aws.String(topicARN),
+		Protocol: aws.String("sqs"),
+		Endpoint: aws.String(queueARN),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao inscrever fila de eventos no tópico: %v", err)
+	}
+	return topicARN, nil
+}
+
+// setupLambdaTarget autoriza o S3 a invocar a função e retorna seu ARN
+func (n *S3NotificationController) setupLambdaTarget(ctx context.Context, bucket, functionName string) (string, error) {
+	getFunctionOutput, err := n.lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao buscar função Lambda: %v", err)
+	}
+	functionARN := aws.ToString(getFunctionOutput.Configuration.FunctionArn)
+
+	_, err = n.lambdaClient.AddPermission(ctx, &lambda.AddPermissionInput{
+		FunctionName: aws.String(functionName),
+		StatementId:  aws.String("s3-invoke-" + bucket),
+		Action:       aws.String("lambda:InvokeFunction"),
+		Principal:    aws.String("s3.amazonaws.com"),
+		SourceArn:    aws.String("arn:aws:s3:::" + bucket),
+	})
+	// A permissão já existe quando a configuração é reaplicada
+	if err != nil && apiErrorCode(err) != "ResourceConflictException" {
+		return "", fmt.Errorf("erro ao autorizar invocação da função: %v", err)
+	}
+	return functionARN, nil
+}
+
+func (n *S3NotificationController) PutBucketNotifications(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	var req PutBucketNotificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+		return
+	}
+	if !req.SQS && !req.SNS && req.LambdaFunction == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe ao menos um destino: sqs, sns ou lambda_function"})
+		return
+	}
+	// A fila de eventos é inscrita no tópico, então cada evento chegaria duas vezes a ela
+	if req.SQS && req.SNS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use sqs ou sns, não ambos: a fila de eventos já é inscrita no tópico e receberia cada evento duas vezes"})
+		return
+	}
+	if len(req.Events) == 0 {
+		req.Events = defaultNotificationEvents
+	}
+
+	ctx := context.TODO()
+	s3Events := toS3Events(req.Events)
+	filter := notificationFilter(req.Prefix, req.Suffix)
+	notification := &types.NotificationConfiguration{}
+	targets := gin.H{}
+
+	if req.SQS {
+		queueARN, err := n.setupQueueTarget(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		notification.QueueConfigurations = append(notification.QueueConfigurations, types.QueueConfiguration{
+			QueueArn: aws.String(queueARN),
+			Events:   s3Events,
+			Filter:   filter,
+		})
+		targets["sqs"] = queueARN
+	}
+
+	if req.SNS {
+		topicARN, err := n.setupTopicTarget(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		notification.TopicConfigurations = append(notification.TopicConfigurations, types.TopicConfiguration{
+			TopicArn: aws.String(topicARN),
+			Events:   s3Events,
+			Filter:   filter,
+		})
+		targets["sns"] = topicARN
+	}
+
+	if req.LambdaFunction != "" {
+		functionARN, err := n.setupLambdaTarget(ctx, bucket, req.LambdaFunction)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		notification.LambdaFunctionConfigurations = append(notification.LambdaFunctionConfigurations, types.LambdaFunctionConfiguration{
+			LambdaFunctionArn: aws.String(functionARN),
+			Events:            s3Events,
+			Filter:            filter,
+		})
+		targets["lambda"] = functionARN
+	}
+
+	_, err := n.s3Client.PutBucketNotificationConfiguration(ctx, &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    aws.String(bucket),
+		NotificationConfiguration: notification,
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar notificações: %v", err)})
+		return
+	}
+
+	// Começar a consumir a fila de eventos assim que houver um destino que chega até ela
+	if req.SQS || req.SNS {
+		n.consumer.Start()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notificações configuradas com sucesso",
+		"bucket":  bucket,
+		"events":  req.Events,
+		"targets": targets,
+	})
+}
+
+func (n *S3NotificationController) GetBucketNotifications(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	result, err := n.s3Client.GetBucketNotificationConfiguration(context.TODO(), &s3.GetBucketNotificationConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar notificações: %v", err)})
+		return
+	}
+
+	configurations := make([]gin.H, 0)
+	for _, queue := range result.QueueConfigurations {
+		configurations = append(configurations, gin.H{"type": "sqs", "arn": aws.ToString(queue.QueueArn), "events": queue.Events})
+	}
+	for _, topic := range result.TopicConfigurations {
+		configurations = append(configurations, gin.H{"type": "sns", "arn": aws.ToString(topic.TopicArn), "events": topic.Events})
+	}
+	for _, function := range result.LambdaFunctionConfigurations {
+		configurations = append(configurations, gin.H{"type": "lambda", "arn": aws.ToString(function.LambdaFunctionArn), "events": function.Events})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket":         bucket,
+		"configurations": configurations,
+	})
+}
+
+func (n *S3NotificationController) DeleteBucketNotifications(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	// Uma configuração vazia remove todas as notificações do bucket
+	_, err := n.s3Client.PutBucketNotificationConfiguration(context.TODO(), &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    aws.String(bucket),
+		NotificationConfiguration: &types.NotificationConfiguration{},
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao remover notificações: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notificações removidas com sucesso",
+	})
+}
+
+// ListEvents apenas lê os registros em memória; o consumidor só é iniciado ao configurar uma notificação
+// com destino sqs ou sns, então sem notificações configuradas a lista fica vazia
+func (n *S3NotificationController) ListEvents(c *gin.Context) {
+	bucket := c.Query("bucket")
+	eventName := c.Query("event")
+
+	records := make([]events.S3EventRecord, 0)
+	for _, record := range n.consumer.Records() {
+		if bucket != "" && record.S3.Bucket.Name != bucket {
+			continue
+		}
+		// event aceita o nome completo (ObjectCreated:Put) ou apenas a categoria (ObjectCreated)
+		if eventName != "" && !strings.HasPrefix(record.EventName, eventName) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(records),
+		"records": records,
+	})
+}
+
+func (n *S3NotificationController) ClearEvents(c *gin.Context) {
+	n.consumer.Clear()
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Eventos removidos com sucesso",
+	})
+}
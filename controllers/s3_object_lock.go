@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+// bypassGovernanceParam indica se a operação deve ignorar retenções em modo GOVERNANCE
+// (exige a permissão s3:BypassGovernanceRetention)
+func bypassGovernanceParam(c *gin.Context) *bool {
+	if c.Query("bypass_governance") == "true" {
+		return aws.Bool(true)
+	}
+	return nil
+}
+
+// Retenção padrão aplicada a novos objetos; informe days ou years. Sem mode, a retenção padrão é removida
+type PutObjectLockConfigurationRequest struct {
+	Mode  string `json:"mode" binding:"omitempty,oneof=GOVERNANCE COMPLIANCE"`
+	Days  int32  `json:"days"`
+	Years int32  `json:"years"`
+}
+
+func (r PutObjectLockConfigurationRequest) Validate() error {
+	if r.Mode == "" {
+		if r.Days != 0 || r.Years != 0 {
+			return fmt.Errorf("informe mode junto com days ou years")
+		}
+		return nil
+	}
+	if r.Days < 0 || r.Years < 0 {
+		return fmt.Errorf("days e years devem ser positivos")
+	}
+	if (r.Days > 0) == (r.Years > 0) {
+		return fmt.Errorf("informe apenas days ou years")
+	}
+	return nil
+}
+
+func (s *S3Controller) PutObjectLockConfiguration(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	var req PutObjectLockConfigurationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mode deve ser GOVERNANCE ou COMPLIANCE"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	configuration := &types.ObjectLockConfiguration{
+		ObjectLockEnabled: types.ObjectLockEnabledEnabled,
+	}
+	if req.Mode != "" {
+		retention := &types.DefaultRetention{
+			Mode: types.ObjectLockRetentionMode(req.Mode),
+		}
+		if req.Days > 0 {
+			retention.Days = aws.Int32(req.Days)
+		} else {
+			retention.Years = aws.Int32(req.Years)
+		}
+		configuration.Rule = &types.ObjectLockRule{DefaultRetention: retention}
+	}
+
+	_, err := s.client.PutObjectLockConfiguration(context.TODO(), &s3.PutObjectLockConfigurationInput{
+		Bucket:                  aws.String(bucket),
+		ObjectLockConfiguration: configuration,
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar Object Lock: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Object Lock configurado com sucesso",
+		"bucket":  bucket,
+		"mode":    req.Mode,
+		"days":    req.Days,
+		"years":   req.Years,
+	})
+}
+
+func (s *S3Controller) GetObjectLockConfiguration(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	result, err := s.client.GetObjectLockConfiguration(context.TODO(), &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if apiErrorCode(err) == "ObjectLockConfigurationNotFoundError" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bucket sem Object Lock habilitado"})
+			return
+		}
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar Object Lock: %v", err)})
+		return
+	}
+
+	response := gin.H{
+		"bucket":  bucket,
+		"enabled": false,
+	}
+	if configuration := result.ObjectLockConfiguration; configuration != nil {
+		response["enabled"] = configuration.ObjectLockEnabled == types.ObjectLockEnabledEnabled
+		if configuration.Rule != nil && configuration.Rule.DefaultRetention != nil {
+			retention := configuration.Rule.DefaultRetention
+			response["default_retention"] = gin.H{
+				"mode":  retention.Mode,
+				"days":  aws.ToInt32(retention.Days),
+				"years": aws.ToInt32(retention.Years),
+			}
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type PutObjectRetentionRequest struct {
+	Mode        string    `json:"mode" binding:"required,oneof=GOVERNANCE COMPLIANCE"`
+	RetainUntil time.Time `json:"retain_until" binding:"required"`
+}
+
+func (s *S3Controller) PutObjectRetention(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	var req PutObjectRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe mode (GOVERNANCE ou COMPLIANCE) e retain_until (RFC 3339)"})
+		return
+	}
+	if !req.RetainUntil.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "retain_until deve estar no futuro"})
+		return
+	}
+
+	// Reduzir ou remover uma retenção GOVERNANCE exige bypass_governance=true; COMPLIANCE só pode ser estendida
+	_, err := s.client.PutObjectRetention(context.TODO(), &s3.PutObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIDParam(c),
+		Retention: &types.ObjectLockRetention{
+			Mode:            types.ObjectLockRetentionMode(req.Mode),
+			RetainUntilDate: aws.Time(req.RetainUntil),
+		},
+		BypassGovernanceRetention: bypassGovernanceParam(c),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar retenção: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Retenção configurada com sucesso",
+		"key":          key,
+		"mode":         req.Mode,
+		"retain_until": req.RetainUntil,
+	})
+}
+
+func (s *S3Controller) GetObjectRetention(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	result, err := s.client.GetObjectRetention(context.TODO(), &s3.GetObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIDParam(c),
+	})
+	if err != nil {
+		if apiErrorCode(err) == "NoSuchObjectLockConfiguration" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Objeto sem retenção configurada"})
+			return
+		}
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar retenção: %v", err)})
+		return
+	}
+
+	response := gin.H{"key": key}
+	if result.Retention != nil {
+		response["mode"] = result.Retention.Mode
+		response["retain_until"] = aws.ToTime(result.Retention.RetainUntilDate)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type PutObjectLegalHoldRequest struct {
+	Status string `json:"status" binding:"required,oneof=ON OFF"`
+}
+
+func (s *S3Controller) PutObjectLegalHold(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	var req PutObjectLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status deve ser ON ou OFF"})
+		return
+	}
+
+	_, err := s.client.PutObjectLegalHold(context.TODO(), &s3.PutObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIDParam(c),
+		LegalHold: &types.ObjectLockLegalHold{
+			Status: types.ObjectLockLegalHoldStatus(req.Status),
+		},
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar legal hold: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Legal hold configurado com sucesso",
+		"key":     key,
+		"status":  req.Status,
+	})
+}
+
+func (s *S3Controller) GetObjectLegalHold(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	result, err := s.client.GetObjectLegalHold(context.TODO(), &s3.GetObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: versionIDParam(c),
+	})
+	if err != nil {
+		if apiErrorCode(err) == "NoSuchObjectLockConfiguration" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Objeto sem legal hold configurado"})
+			return
+		}
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar legal hold: %v", err)})
+		return
+	}
+
+	status := types.ObjectLockLegalHoldStatusOff
+	if result.LegalHold != nil {
+		status = result.LegalHold.Status
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"key":    key,
+		"status": status,
+	})
+}
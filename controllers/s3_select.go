@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"localstackdemo/s3select"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SelectEngineAuto  = "auto"
+	SelectEngineS3    = "s3"
+	SelectEngineLocal = "local"
+)
+
+// Registros escritos pelo avaliador local entre cada envio ao cliente
+const selectFlushInterval = 100
+
+type SelectRequest struct {
+	Expression string `json:"expression" binding:"required"`
+	// csv ou json (um objeto por linha); inferido pela extensão quando omitido
+	InputFormat string `json:"input_format"`
+	// USE, IGNORE ou NONE para a primeira linha do CSV
+	CSVHeader string `json:"csv_header"`
+	Delimiter string `json:"delimiter"`
+	// auto usa o S3 Select e recorre ao avaliador local quando o backend não o suporta
+	Engine string `json:"engine"`
+}
+
+func (r *SelectRequest) applyDefaults(key string) {
+	ext := strings.ToLower(path.Ext(key))
+	if r.InputFormat == "" {
+		r.InputFormat = s3select.FormatJSON
+		if ext == ".csv" || ext == ".tsv" {
+			r.InputFormat = s3select.FormatCSV
+		}
+	}
+	r.InputFormat = strings.ToLower(r.InputFormat)
+	r.CSVHeader = strings.ToUpper(r.CSVHeader)
+	if r.CSVHeader == "" {
+		r.CSVHeader = s3select.HeaderUse
+	}
+	if r.Delimiter == "" {
+		r.Delimiter = ","
+		if ext == ".tsv" {
+			r.Delimiter = "\t"
+		}
+	}
+	if r.Engine == "" {
+		r.Engine = SelectEngineAuto
+	}
+}
+
+func (r SelectRequest) input() s3select.Input {
+	return s3select.Input{Format: r.InputFormat, CSVHeader: r.CSVHeader, Delimiter: r.Delimiter}
+}
+
+// isSelectUnsupportedError identifica backends sem S3 Select, como o LocalStack community
+func isSelectUnsupportedError(err error) bool {
+	switch apiErrorCode(err) {
+	case "NotImplemented", "UnsupportedOperation", "MethodNotAllowed":
+		return true
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &statusErr) && statusErr.HTTPStatusCode() == http.StatusNotImplemented
+}
+
+// selectError responde com erro em JSON, descartando os headers de streaming definidos por uma tentativa anterior
+func selectError(c *gin.Context, status int, message string) {
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("X-Select-Engine")
+	c.JSON(status, gin.H{"error": message})
+}
+
+func (s *S3Controller) SelectObjectContent(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	var req SelectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expressão SQL é obrigatória"})
+		return
+	}
+	req.applyDefaults(key)
+	if err := req.input().Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Engine != SelectEngineAuto && req.Engine != SelectEngineS3 && req.Engine != SelectEngineLocal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Engine inválida: %s (use auto, s3 ou local)", req.Engine)})
+		return
+	}
+
+	customer, err := customerKeyFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.Engine != SelectEngineLocal {
+		err := s.selectWithS3(ctx, c, bucket, key, req, customer)
+		switch {
+		case err == nil:
+			return
+		case c.Writer.Written():
+			// Os registros já enviados não podem ser desfeitos; a resposta apenas termina mais cedo
+			log.Printf("Erro durante a consulta em %s/%s: %v", bucket, key, err)
+			return
+		case req.Engine == SelectEngineS3 || !isSelectUnsupportedError(err):
+			selectError(c, s3ErrorStatus(err), fmt.Sprintf("Erro ao executar consulta: %v", err))
+			return
+		}
+	}
+
+	s.selectLocally(ctx, c, bucket, key, req, customer)
+}
+
+// selectWithS3 executa a consulta no S3 Select e repassa os registros em JSON lines conforme chegam
+func (s *S3Controller) selectWithS3(ctx context.Context, c *gin.Context, bucket, key string, req SelectRequest, customer *customerKey) error {
+	input := &s3.SelectObjectContentInput{
+		Bucket:         aws.String(bucket),
+		Key:            aws.String(key),
+		Expression:     aws.String(req.Expression),
+		ExpressionType: types.ExpressionTypeSql,
+		OutputSerialization: &types.OutputSerialization{
+			JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")},
+		},
+	}
+	if req.InputFormat == s3select.FormatCSV {
+		input.InputSerialization = &types.InputSerialization{
+			CSV: &types.CSVInput{
+				FileHeaderInfo: types.FileHeaderInfo(req.CSVHeader),
+				FieldDelimiter: aws.String(req.Delimiter),
+			},
+		}
+	} else {
+		input.InputSerialization = &types.InputSerialization{
+			JSON: &types.JSONInput{Type: types.JSONTypeLines},
+		}
+	}
+	if customer != nil {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = customer.Key
+		input.SSECustomerKeyMD5 = customer.MD5
+	}
+
+	output, err := s.client.SelectObjectContent(ctx, input)
+	if err != nil {
+		return err
+	}
+	stream := output.GetStream()
+	defer stream.Close()
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("X-Select-Engine", SelectEngineS3)
+	for event := range stream.Events() {
+		if records, ok := event.(*types.SelectObjectContentEventStreamMemberRecords); ok {
+			if _, err := c.Writer.Write(records.Value.Payload); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+
+	// Consultas sem resultados ainda precisam responder 200
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	return nil
+}
+
+// selectLocally baixa o objeto e avalia a consulta com o avaliador em Go, limitado a SELECT/WHERE/LIMIT simples
+func (s *S3Controller) selectLocally(ctx context.Context, c *gin.Context, bucket, key string, req SelectRequest, customer *customerKey) {
+	query, err := s3select.Parse(req.Expression)
+	if err != nil {
+		selectError(c, http.StatusBadRequest, fmt.Sprintf("Expressão não suportada pelo avaliador local: %v", err))
+		return
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if customer != nil {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = customer.Key
+		input.SSECustomerKeyMD5 = customer.MD5
+	}
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		selectError(c, s3ErrorStatus(err), fmt.Sprintf("Erro ao baixar objeto: %v", err))
+		return
+	}
+	defer result.Body.Close()
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("X-Select-Engine", SelectEngineLocal)
+	count := 0
+	err = query.Run(result.Body, req.input(), func(row s3select.Row) error {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if _, err := c.Writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		if count++; count%selectFlushInterval == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !c.Writer.Written() {
+			selectError(c, http.StatusUnprocessableEntity, fmt.Sprintf("Erro ao processar objeto: %v", err))
+			return
+		}
+		log.Printf("Erro durante a consulta local em %s/%s: %v", bucket, key, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+}
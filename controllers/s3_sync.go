@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+
+	"localstackdemo/s3sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SyncRequest struct {
+	LocalPath   string `json:"local_path" binding:"required"`
+	Bucket      string `json:"bucket"`
+	Prefix      string `json:"prefix"`
+	Direction   string `json:"direction" binding:"required,oneof=upload download"`
+	Delete      bool   `json:"delete"`
+	DryRun      bool   `json:"dry_run"`
+	Concurrency int    `json:"concurrency" binding:"omitempty,min=1,max=64"`
+}
+
+// resolveSyncPath garante que o caminho informado fique dentro do diretório base de sincronização
+func (s *S3Controller) resolveSyncPath(localPath string) (string, bool) {
+	root, err := filepath.Abs(s.syncRoot)
+	if err != nil {
+		return "", false
+	}
+	target := localPath
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	rel, err := filepath.Rel(root, filepath.Clean(target))
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.Join(root, rel), true
+}
+
+func (s *S3Controller) SyncDirectory(c *gin.Context) {
+	// O endpoint lê e apaga arquivos do servidor, então só fica disponível com um diretório dedicado configurado
+	if s.syncRoot == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Sincronização desativada: defina S3_SYNC_ROOT com um diretório dedicado"})
+		return
+	}
+
+	var req SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "local_path e direction (upload ou download) são obrigatórios"})
+		return
+	}
+
+	localPath, ok := s.resolveSyncPath(req.LocalPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "local_path deve estar dentro do diretório de sincronização"})
+		return
+	}
+
+	if req.Bucket == "" {
+		if err := s.setupBucket(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		req.Bucket = s.defaultBucket
+	}
+
+	result, err := s.syncer.Run(context.TODO(), s3sync.Options{
+		LocalDir:    localPath,
+		Bucket:      req.Bucket,
+		Prefix:      req.Prefix,
+		Direction:   s3sync.Direction(req.Direction),
+		Delete:      req.Delete,
+		DryRun:      req.DryRun,
+		Concurrency: req.Concurrency,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, result)
+}
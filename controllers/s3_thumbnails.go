@@ -0,0 +1,402 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	thumbnailPrefix = "thumbnails/"
+	// Uploads aguardando processamento e geradores rodando em paralelo
+	thumbnailQueueSize = 100
+	thumbnailWorkers   = 2
+	// Limite de pixels da imagem original, para não descomprimir imagens gigantes na memória
+	maxThumbnailSourcePixels = 50_000_000
+	// Quantidade máxima de jobs mantidos em memória
+	maxStoredThumbnailJobs = 1000
+)
+
+const (
+	ThumbnailPending    = "pending"
+	ThumbnailProcessing = "processing"
+	ThumbnailDone       = "done"
+	ThumbnailFailed     = "failed"
+)
+
+// Formatos suportados pelos pacotes image da biblioteca padrão
+var thumbnailContentTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+type ThumbnailJob struct {
+	Bucket      string            `json:"bucket"`
+	Key         string            `json:"key"`
+	Status      string            `json:"status"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+	Error       string            `json:"error,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+
+	contentType string
+}
+
+// ThumbnailGenerator gera em segundo plano as miniaturas das imagens enviadas e acompanha o status de cada uma
+type ThumbnailGenerator struct {
+	client *s3.Client
+	sizes  []int
+	queue  chan *ThumbnailJob
+
+	// Quando lifetime é cancelado, os workers esvaziam a fila e terminam; as miniaturas usam work,
+	// que só é cancelado se o prazo de Shutdown expirar
+	lifetime   context.Context
+	work       context.Context
+	cancelWork context.CancelFunc
+	startOnce  sync.Once
+	workers    sync.WaitGroup
+
+	mu    sync.RWMutex
+	jobs  map[string]*ThumbnailJob
+	order []string
+}
+
+func NewThumbnailGenerator(lifetime context.Context, client *s3.Client, sizes []int) *ThumbnailGenerator {
+	work, cancelWork := context.WithCancel(context.WithoutCancel(lifetime))
+	return &ThumbnailGenerator{
+		client:     client,
+		sizes:      sizes,
+		queue:      make(chan *ThumbnailJob, thumbnailQueueSize),
+		lifetime:   lifetime,
+		work:       work,
+		cancelWork: cancelWork,
+		jobs:       make(map[string]*ThumbnailJob),
+		order:      make([]string, 0),
+	}
+}
+
+func thumbnailJobID(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// thumbnailKey mantém a chave original sob o prefixo de miniaturas, separada pelo tamanho
+func thumbnailKey(key string, size int) string {
+	return fmt.Sprintf("%s%d/%s", thumbnailPrefix, size, key)
+}
+
+// Supports indica se o upload deve gerar miniaturas
+func (g *ThumbnailGenerator) Supports(key, contentType string) bool {
+	_, ok := thumbnailContentTypes[contentType]
+	return ok && len(g.sizes) > 0 && !strings.HasPrefix(key, thumbnailPrefix)
+}
+
+// Enqueue registra o job como pendente e o coloca na fila de processamento
+func (g *ThumbnailGenerator) Enqueue(bucket, key, contentType string) *ThumbnailJob {
+	g.startOnce.Do(func() {
+		g.workers.Add(thumbnailWorkers)
+		for i := 0; i < thumbnailWorkers; i++ {
+			go g.run()
+		}
+	})
+
+	job := &ThumbnailJob{
+		Bucket:      bucket,
+		Key:         key,
+		Status:      ThumbnailPending,
+		Thumbnails:  map[string]string{},
+		CreatedAt:   time.Now(),
+		contentType: contentType,
+	}
+	g.store(job)
+
+	if g.lifetime.Err() != nil {
+		g.finish(job, nil, fmt.Errorf("servidor em encerramento"))
+		return g.Job(bucket, key)
+	}
+	select {
+	case g.queue <- job:
+	default:
+		g.finish(job, nil, fmt.Errorf("fila de miniaturas cheia"))
+	}
+	return g.Job(bucket, key)
+}
+
+func (g *ThumbnailGenerator) store(job *ThumbnailJob) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := thumbnailJobID(job.Bucket, job.Key)
+	if _, exists := g.jobs[id]; !exists {
+		g.order = append(g.order, id)
+	}
+	g.jobs[id] = job
+
+	for len(g.order) > maxStoredThumbnailJobs {
+		delete(g.jobs, g.order[0])
+		g.order = g.order[1:]
+	}
+}
+
+// Job retorna uma cópia do job do objeto, ou nil se ele não estiver em memória
+func (g *ThumbnailGenerator) Job(bucket, key string) *ThumbnailJob {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	job, ok := g.jobs[thumbnailJobID(bucket, key)]
+	if !ok {
+		return nil
+	}
+	copied := *job
+	copied.Thumbnails = make(map[string]string, len(job.Thumbnails))
+	for size, key := range job.Thumbnails {
+		copied.Thumbnails[size] = key
+	}
+	return &copied
+}
+
+func (g *ThumbnailGenerator) setStatus(job *ThumbnailJob, status string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	job.Status = status
+}
+
+func (g *ThumbnailGenerator) finish(job *ThumbnailJob, thumbnails map[string]string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	job.CompletedAt = &now
+	for size, key := range thumbnails {
+		job.Thumbnails[size] = key
+	}
+	if err != nil {
+		job.Status = ThumbnailFailed
+		job.Error = err.Error()
+		return
+	}
+	job.Status = ThumbnailDone
+}
+
+func (g *ThumbnailGenerator) run() {
+	defer g.workers.Done()
+	for {
+		select {
+		case job := <-g.queue:
+			g.process(job)
+		case <-g.lifetime.Done():
+			// No encerramento, os jobs que já estavam na fila ainda são processados antes de sair
+			for {
+				select {
+				case job := <-g.queue:
+					g.process(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (g *ThumbnailGenerator) process(job *ThumbnailJob) {
+	g.setStatus(job, ThumbnailProcessing)
+	thumbnails, err := g.generate(g.work, job)
+	if err != nil {
+		log.Printf("Erro ao gerar miniaturas de %s/%s: %v", job.Bucket, job.Key, err)
+	}
+	g.finish(job, thumbnails, err)
+}
+
+// Shutdown aguarda os workers esvaziarem a fila, depois que o contexto de vida do servidor foi cancelado.
+// Se ctx expirar antes, os envios em andamento são cancelados e os jobs restantes falham
+func (g *ThumbnailGenerator) Shutdown(ctx context.Context) error {
+	// Garante que um Enqueue concorrente termine de registrar os workers antes da espera
+	g.startOnce.Do(func() {})
+
+	done := make(chan struct{})
+	go func() {
+		g.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		g.cancelWork()
+		return fmt.Errorf("geração de miniaturas interrompida: %v", ctx.Err())
+	}
+}
+
+// generate baixa a imagem original e envia uma miniatura para cada tamanho configurado
+func (g *ThumbnailGenerator) generate(ctx context.Context, job *ThumbnailJob) (map[string]string, error) {
+	result, err := g.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(job.Bucket),
+		Key:    aws.String(job.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar imagem: %v", err)
+	}
+	data, err := io.ReadAll(result.Body)
+	result.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler imagem: %v", err)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler dimensões da imagem: %v", err)
+	}
+	if config.Width*config.Height > maxThumbnailSourcePixels {
+		return nil, fmt.Errorf("imagem muito grande: %dx%d", config.Width, config.Height)
+	}
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar imagem: %v", err)
+	}
+
+	thumbnails := make(map[string]string, len(g.sizes))
+	for _, size := range g.sizes {
+		var buf bytes.Buffer
+		if err := encodeThumbnail(&buf, resizeImage(source, size), job.contentType); err != nil {
+			return thumbnails, fmt.Errorf("erro ao codificar miniatura de %dpx: %v", size, err)
+		}
+
+		key := thumbnailKey(job.Key, size)
+		_, err := g.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(job.Bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(buf.Bytes()),
+			ContentType: aws.String(job.contentType),
+			Metadata:    map[string]string{"thumbnail-of": job.Key},
+		})
+		if err != nil {
+			return thumbnails, fmt.Errorf("erro ao enviar miniatura de %dpx: %v", size, err)
+		}
+		thumbnails[strconv.Itoa(size)] = key
+	}
+	return thumbnails, nil
+}
+
+func encodeThumbnail(w io.Writer, img image.Image, contentType string) error {
+	switch thumbnailContentTypes[contentType] {
+	case "png":
+		return png.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, nil)
+	default:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+}
+
+// resizeImage reduz a imagem para que o lado maior tenha no máximo size pixels, pela média de cada bloco
+// de pixels de origem; imagens menores não são ampliadas
+func resizeImage(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	dstWidth, dstHeight := size, height*size/width
+	if height > width {
+		dstWidth, dstHeight = width*size/height, size
+	}
+	dstWidth, dstHeight = max(dstWidth, 1), max(dstHeight, 1)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(bounds.Min.Y+(y+1)*height/dstHeight, y0+1)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(bounds.Min.X+(x+1)*width/dstWidth, x0+1)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(pixel.R)
+					g += uint64(pixel.G)
+					b += uint64(pixel.B)
+					a += uint64(pixel.A)
+					count++
+				}
+			}
+			dst.Set(x, y, color.NRGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+	return dst
+}
+
+// storedThumbnails procura no bucket as miniaturas de objetos processados antes do último restart
+func (g *ThumbnailGenerator) storedThumbnails(ctx context.Context, bucket, key string) (map[string]string, error) {
+	thumbnails := make(map[string]string)
+	for _, size := range g.sizes {
+		thumbKey := thumbnailKey(key, size)
+		_, err := g.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(thumbKey),
+		})
+		if err != nil {
+			if s3ErrorStatus(err) == http.StatusNotFound {
+				continue
+			}
+			return nil, err
+		}
+		thumbnails[strconv.Itoa(size)] = thumbKey
+	}
+	return thumbnails, nil
+}
+
+func (s *S3Controller) GetThumbnails(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	if job := s.thumbnails.Job(bucket, key); job != nil {
+		c.JSON(http.StatusOK, job)
+		return
+	}
+
+	thumbnails, err := s.thumbnails.storedThumbnails(context.TODO(), bucket, key)
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar miniaturas: %v", err)})
+		return
+	}
+	if len(thumbnails) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Nenhuma miniatura encontrada para %s", key)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket":     bucket,
+		"key":        key,
+		"status":     ThumbnailDone,
+		"thumbnails": thumbnails,
+	})
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+)
+
+// copySource monta o valor de CopySource (bucket/chave[?versionId=]) com a chave codificada
+func copySource(bucket, key, versionID string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	source := bucket + "/" + strings.Join(segments, "/")
+	if versionID != "" {
+		source += "?versionId=" + url.QueryEscape(versionID)
+	}
+	return source
+}
+
+// versionIDParam retorna o version_id da query string ou nil quando ausente
+func versionIDParam(c *gin.Context) *string {
+	if versionID := c.Query("version_id"); versionID != "" {
+		return aws.String(versionID)
+	}
+	return nil
+}
+
+type PutBucketVersioningRequest struct {
+	Status string `json:"status" binding:"required,oneof=Enabled Suspended"`
+}
+
+func (s *S3Controller) PutBucketVersioning(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	var req PutBucketVersioningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status deve ser Enabled ou Suspended"})
+		return
+	}
+
+	_, err := s.client.PutBucketVersioning(context.TODO(), &s3.PutBucketVersioningInput{
+		Bucket: aws.String(bucket),
+		VersioningConfiguration: &types.VersioningConfiguration{
+			Status: types.BucketVersioningStatus(req.Status),
+		},
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao configurar versionamento: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Versionamento configurado com sucesso",
+		"bucket":  bucket,
+		"status":  req.Status,
+	})
+}
+
+func (s *S3Controller) GetBucketVersioning(c *gin.Context) {
+	bucket := c.Param("bucket")
+
+	result, err := s.client.GetBucketVersioning(context.TODO(), &s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao consultar versionamento: %v", err)})
+		return
+	}
+
+	// Buckets que nunca tiveram versionamento retornam status vazio
+	status := string(result.Status)
+	if status == "" {
+		status = "Disabled"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"status": status,
+	})
+}
+
+func (s *S3Controller) ListObjectVersions(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	versions := make([]gin.H, 0)
+	paginator := s3.NewListObjectVersionsPaginator(s.client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(key),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao listar versões: %v", err)})
+			return
+		}
+
+		// O prefixo também retorna chaves mais longas, então filtramos pela chave exata
+		for _, version := range page.Versions {
+			if aws.ToString(version.Key) != key {
+				continue
+			}
+			versions = append(versions, gin.H{
+				"version_id":    aws.ToString(version.VersionId),
+				"is_latest":     aws.ToBool(version.IsLatest),
+				"size":          aws.ToInt64(version.Size),
+				"etag":          aws.ToString(version.ETag),
+				"last_modified": aws.ToTime(version.LastModified),
+				"delete_marker": false,
+			})
+		}
+		for _, marker := range page.DeleteMarkers {
+			if aws.ToString(marker.Key) != key {
+				continue
+			}
+			versions = append(versions, gin.H{
+				"version_id":    aws.ToString(marker.VersionId),
+				"is_latest":     aws.ToBool(marker.IsLatest),
+				"last_modified": aws.ToTime(marker.LastModified),
+				"delete_marker": true,
+			})
+		}
+	}
+
+	// Versões e delete markers vêm em listas separadas; o histórico é exibido do mais recente ao mais antigo
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i]["last_modified"].(time.Time).After(versions[j]["last_modified"].(time.Time))
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket":   bucket,
+		"key":      key,
+		"versions": versions,
+	})
+}
+
+type RestoreVersionRequest struct {
+	VersionID string `json:"version_id" binding:"required"`
+}
+
+func (s *S3Controller) RestoreObjectVersion(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := objectKeyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chave do objeto é obrigatória"})
+		return
+	}
+
+	var req RestoreVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version_id é obrigatório"})
+		return
+	}
+
+	// Copiar a versão antiga sobre a própria chave a torna a versão atual
+	result, err := s.client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(copySource(bucket, key, req.VersionID)),
+	})
+	if err != nil {
+		c.JSON(s3ErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao restaurar versão: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Versão restaurada com sucesso",
+		"key":                 key,
+		"restored_version_id": req.VersionID,
+		"version_id":          aws.ToString(result.VersionId),
+	})
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Limites do SQS para operações em lote e para o visibility timeout (12 horas)
+const (
+	maxSQSBatchEntries   = 10
+	maxVisibilityTimeout = 43200
+)
+
+// messageResponse monta a resposta de uma mensagem recebida, com o receipt handle usado para confirmá-la
+func messageResponse(message types.Message) gin.H {
+	response := gin.H{
+		"message":            aws.ToString(message.Body),
+		"message_id":         aws.ToString(message.MessageId),
+		"receipt_handle":     aws.ToString(message.ReceiptHandle),
+		"attributes":         message.Attributes,
+		"message_attributes": messageAttributesResponse(message.MessageAttributes),
+	}
+	if body, ok := jsonBody(message); ok {
+		response["body"] = body
+	}
+	return response
+}
+
+// messageAttributesResponse converte os atributos de mensagem do SQS em {nome: {data_type, value}};
+// valores binários são serializados em base64
+func messageAttributesResponse(attributes map[string]types.MessageAttributeValue) gin.H {
+	response := gin.H{}
+	for name, attribute := range attributes {
+		var value any = aws.ToString(attribute.StringValue)
+		if attribute.BinaryValue != nil {
+			value = attribute.BinaryValue
+		}
+		response[name] = gin.H{
+			"data_type": aws.ToString(attribute.DataType),
+			"value":     value,
+		}
+	}
+	return response
+}
+
+type AckMessageRequest struct {
+	ReceiptHandle string `json:"receipt_handle" binding:"required"`
+}
+
+// AckMessage remove da fila uma mensagem recebida, confirmando seu processamento
+func (s *SQSController) AckMessage(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	var req AckMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Receipt handle é obrigatório"})
+		return
+	}
+
+	_, err := s.client.DeleteMessage(context.TODO(), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: aws.String(req.ReceiptHandle),
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao confirmar mensagem", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mensagem confirmada com sucesso",
+	})
+}
+
+type AckMessageBatchRequest struct {
+	ReceiptHandles []string `json:"receipt_handles" binding:"required,min=1"`
+}
+
+// AckMessageBatch confirma várias mensagens, em lotes de 10, e retorna o resultado de cada uma
+func (s *SQSController) AckMessageBatch(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	var req AckMessageBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe ao menos um receipt handle"})
+		return
+	}
+
+	failures, err := s.deleteMessageBatch(context.TODO(), queueURL, req.ReceiptHandles)
+	// Se algum lote já foi removido, o resultado por mensagem é retornado mesmo com o erro
+	if err != nil && len(failures) == len(req.ReceiptHandles) {
+		s.sqsError(c, name, "Erro ao confirmar mensagens", err)
+		return
+	}
+
+	results := make([]gin.H, len(req.ReceiptHandles))
+	for i, receiptHandle := range req.ReceiptHandles {
+		results[i] = gin.H{"receipt_handle": receiptHandle, "success": true}
+		if failure, found := failures[i]; found {
+			results[i]["success"] = false
+			results[i]["error"] = failure
+		}
+	}
+
+	status := http.StatusOK
+	if len(failures) > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"results":   results,
+		"succeeded": len(results) - len(failures),
+		"failed":    len(failures),
+	})
+}
+
+// deleteMessageBatch remove as mensagens em lotes de 10 e retorna o erro de cada falha, indexado pela posição.
+// Se uma chamada falhar, o lote dela e os seguintes são marcados como falha e o erro é retornado junto,
+// para que o chamador ainda saiba quais mensagens dos lotes anteriores foram removidas
+func (s *SQSController) deleteMessageBatch(ctx context.Context, queueURL string, receiptHandles []string) (map[int]string, error) {
+	failures := make(map[int]string)
+	for start := 0; start < len(receiptHandles); start += maxSQSBatchEntries {
+		end := min(start+maxSQSBatchEntries, len(receiptHandles))
+
+		// O Id de cada entrada é a posição do receipt handle na lista
+		entries := make([]types.DeleteMessageBatchRequestEntry, 0, end-start)
+		for i := start; i < end; i++ {
+			entries = append(entries, types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: aws.String(receiptHandles[i]),
+			})
+		}
+
+		output, err := s.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			for i := start; i < len(receiptHandles); i++ {
+				failures[i] = err.Error()
+			}
+			return failures, err
+		}
+		for _, entry := range output.Failed {
+			if i, err := strconv.Atoi(aws.ToString(entry.Id)); err == nil {
+				failures[i] = aws.ToString(entry.Message)
+			}
+		}
+	}
+	return failures, nil
+}
+
+type ChangeVisibilityRequest struct {
+	ReceiptHandle string `json:"receipt_handle" binding:"required"`
+	// Ponteiro para diferenciar o campo ausente de zero, que devolveria a mensagem à fila
+	VisibilityTimeout *int32 `json:"visibility_timeout" binding:"required"`
+}
+
+// ChangeVisibility estende o prazo de processamento de uma mensagem recebida
+func (s *SQSController) ChangeVisibility(c *gin.Context) {
+	var req ChangeVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "receipt_handle e visibility_timeout são obrigatórios"})
+		return
+	}
+	if *req.VisibilityTimeout == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visibility_timeout deve ser maior que zero; use /nack para devolver a mensagem à fila"})
+		return
+	}
+	s.changeVisibility(c, req.ReceiptHandle, *req.VisibilityTimeout, "Visibilidade da mensagem alterada com sucesso")
+}
+
+type NackMessageRequest struct {
+	ReceiptHandle string `json:"receipt_handle" binding:"required"`
+	// Segundos até a mensagem voltar a ser entregue; zero a devolve imediatamente
+	DelaySeconds int32 `json:"delay_seconds"`
+}
+
+// NackMessage devolve uma mensagem à fila sem confirmá-la, opcionalmente após um atraso
+func (s *SQSController) NackMessage(c *gin.Context) {
+	var req NackMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Receipt handle é obrigatório"})
+		return
+	}
+	s.changeVisibility(c, req.ReceiptHandle, req.DelaySeconds, "Mensagem devolvida à fila com sucesso")
+}
+
+func (s *SQSController) changeVisibility(c *gin.Context, receiptHandle string, timeout int32, message string) {
+	if timeout < 0 || timeout > maxVisibilityTimeout {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O tempo deve estar entre 0 e 43200 segundos"})
+		return
+	}
+
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	_, err := s.client.ChangeMessageVisibility(context.TODO(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: timeout,
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao alterar visibilidade da mensagem", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            message,
+		"visibility_timeout": timeout,
+	})
+}
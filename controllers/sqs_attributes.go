@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Limites do SQS para atributos de mensagem e para o atraso de entrega (15 minutos)
+const (
+	maxMessageAttributes      = 10
+	maxMessageAttributeLength = 256
+	maxDelaySeconds           = 900
+)
+
+// Atributo adicionado às mensagens enviadas com body JSON, usado para decodificá-lo no recebimento
+const (
+	contentTypeAttribute = "content-type"
+	jsonContentType      = "application/json"
+)
+
+var messageAttributeNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// MessageAttribute é um atributo tipado da mensagem; data_type é String, Number ou Binary,
+// opcionalmente com um sufixo (ex.: Number.int), e valores Binary são informados em base64
+type MessageAttribute struct {
+	DataType string `json:"data_type"`
+	Value    string `json:"value"`
+}
+
+func validateMessageAttribute(name string, attribute MessageAttribute) error {
+	lower := strings.ToLower(name)
+	switch {
+	case len(name) > maxMessageAttributeLength || !messageAttributeNamePattern.MatchString(name):
+		return fmt.Errorf("nome de atributo inválido %q: use até %d letras, números, _, - ou .", name, maxMessageAttributeLength)
+	case strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") || strings.Contains(name, ".."):
+		return fmt.Errorf("nome de atributo inválido %q: não pode começar ou terminar com ponto nem ter pontos seguidos", name)
+	case strings.HasPrefix(lower, "aws.") || strings.HasPrefix(lower, "amazon."):
+		return fmt.Errorf("nome de atributo inválido %q: os prefixos AWS. e Amazon. são reservados", name)
+	}
+
+	if len(attribute.DataType) > maxMessageAttributeLength {
+		return fmt.Errorf("atributo %s: data_type excede %d caracteres", name, maxMessageAttributeLength)
+	}
+	if attribute.Value == "" {
+		return fmt.Errorf("atributo %s: value é obrigatório", name)
+	}
+	baseType, _, _ := strings.Cut(attribute.DataType, ".")
+	switch baseType {
+	case "String":
+	case "Number":
+		if _, err := strconv.ParseFloat(attribute.Value, 64); err != nil {
+			return fmt.Errorf("atributo %s: %q não é um número", name, attribute.Value)
+		}
+	case "Binary":
+		if _, err := base64.StdEncoding.DecodeString(attribute.Value); err != nil {
+			return fmt.Errorf("atributo %s: valor Binary deve estar em base64", name)
+		}
+	default:
+		return fmt.Errorf("atributo %s: data_type deve ser String, Number ou Binary", name)
+	}
+	return nil
+}
+
+// hasJSONBody indica se a mensagem usa o modo JSON, em que body substitui message
+func (r SendMessageRequest) hasJSONBody() bool {
+	return len(r.Body) > 0 && string(r.Body) != "null"
+}
+
+// text retorna o corpo enviado ao SQS: message ou o body JSON compactado
+func (r SendMessageRequest) text() string {
+	if !r.hasJSONBody() {
+		return r.Message
+	}
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, r.Body); err != nil {
+		return string(r.Body)
+	}
+	return buffer.String()
+}
+
+// sqsMessageAttributes converte os atributos da requisição para o SQS, incluindo o content-type do modo JSON
+func (r SendMessageRequest) sqsMessageAttributes() map[string]types.MessageAttributeValue {
+	if len(r.Attributes) == 0 && !r.hasJSONBody() {
+		return nil
+	}
+
+	attributes := make(map[string]types.MessageAttributeValue, len(r.Attributes)+1)
+	for name, attribute := range r.Attributes {
+		value := types.MessageAttributeValue{DataType: aws.String(attribute.DataType)}
+		if strings.HasPrefix(attribute.DataType, "Binary") {
+			value.BinaryValue, _ = base64.StdEncoding.DecodeString(attribute.Value)
+		} else {
+			value.StringValue = aws.String(attribute.Value)
+		}
+		attributes[name] = value
+	}
+	if r.hasJSONBody() {
+		attributes[contentTypeAttribute] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(jsonContentType),
+		}
+	}
+	return attributes
+}
+
+// jsonBody retorna o corpo decodificado das mensagens enviadas no modo JSON
+func jsonBody(message types.Message) (json.RawMessage, bool) {
+	contentType, ok := message.MessageAttributes[contentTypeAttribute]
+	if !ok || aws.ToString(contentType.StringValue) != jsonContentType {
+		return nil, false
+	}
+	body := []byte(aws.ToString(message.Body))
+	if !json.Valid(body) {
+		return nil, false
+	}
+	return json.RawMessage(body), true
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Tamanho máximo de uma mensagem e da soma das mensagens de um lote no SQS (256 KB)
+const maxSQSPayloadSize = 256 * 1024
+
+// Quantidade máxima de mensagens retornadas por um receive com max_messages
+const maxReceiveMessages = 100
+
+// Tempo máximo de long polling aceito pelo SQS
+const maxWaitTimeSeconds = 20
+
+type SendMessageBatchRequest struct {
+	Messages []SendMessageRequest `json:"messages" binding:"required,min=1,dive"`
+}
+
+// messageSize estima o tamanho da mensagem como o SQS a contabiliza no limite de 256 KB:
+// o corpo mais nome, tipo e valor de cada atributo
+func messageSize(req SendMessageRequest) int {
+	size := len(req.text())
+	for name, attribute := range req.sqsMessageAttributes() {
+		size += len(name) + len(aws.ToString(attribute.DataType)) +
+			len(aws.ToString(attribute.StringValue)) + len(attribute.BinaryValue)
+	}
+	return size
+}
+
+// batchChunks agrupa as mensagens em lotes de até 10 entradas e 256 KB, preservando a ordem;
+// mensagens que sozinhas excedem o limite são retornadas à parte
+func batchChunks(messages []SendMessageRequest) ([][]int, []int) {
+	chunks := make([][]int, 0)
+	oversized := make([]int, 0)
+
+	current := make([]int, 0, maxSQSBatchEntries)
+	currentSize := 0
+	for i, message := range messages {
+		size := messageSize(message)
+		if size > maxSQSPayloadSize {
+			oversized = append(oversized, i)
+			continue
+		}
+		if len(current) == maxSQSBatchEntries || currentSize+size > maxSQSPayloadSize {
+			chunks = append(chunks, current)
+			current, currentSize = make([]int, 0, maxSQSBatchEntries), 0
+		}
+		current = append(current, i)
+		currentSize += size
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, oversized
+}
+
+// fifoBatchChunks agrupa as mensagens de uma fila FIFO com no máximo uma mensagem de cada grupo por lote,
+// sempre em um lote posterior ao da mensagem anterior do mesmo grupo. Assim, quando uma entrada falha, as
+// seguintes do grupo ainda não foram enviadas e podem ser descartadas
+func fifoBatchChunks(messages []SendMessageRequest) ([][]int, []int) {
+	chunks := make([][]int, 0)
+	sizes := make([]int, 0)
+	oversized := make([]int, 0)
+
+	// Primeiro lote que pode receber a próxima mensagem de cada grupo
+	next := make(map[string]int)
+	for i, message := range messages {
+		size := messageSize(message)
+		if size > maxSQSPayloadSize {
+			oversized = append(oversized, i)
+			continue
+		}
+		chunk := next[message.MessageGroupID]
+		for chunk < len(chunks) && (len(chunks[chunk]) == maxSQSBatchEntries || sizes[chunk]+size > maxSQSPayloadSize) {
+			chunk++
+		}
+		if chunk == len(chunks) {
+			chunks = append(chunks, make([]int, 0, maxSQSBatchEntries))
+			sizes = append(sizes, 0)
+		}
+		chunks[chunk] = append(chunks[chunk], i)
+		sizes[chunk] += size
+		next[message.MessageGroupID] = chunk + 1
+	}
+	return chunks, oversized
+}
+
+// SendMessageBatch envia várias mensagens usando SendMessageBatch e retorna o resultado de cada uma
+func (s *SQSController) SendMessageBatch(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	var req SendMessageBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe ao menos uma mensagem com o campo message ou body"})
+		return
+	}
+	for i, message := range req.Messages {
+		if err := message.Validate(isFIFOQueue(name)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Mensagem %d: %v", i, err)})
+			return
+		}
+	}
+
+	results := make([]gin.H, len(req.Messages))
+	for i := range req.Messages {
+		results[i] = gin.H{"index": i, "success": false}
+	}
+
+	// Em filas FIFO, depois que uma mensagem de um grupo falha as seguintes do mesmo grupo não são enviadas,
+	// para não serem entregues antes dela quando o cliente reenviar a que falhou; fifoBatchChunks garante
+	// que elas estejam em lotes posteriores
+	fifo := isFIFOQueue(name)
+	failedGroups := make(map[string]int)
+	groupFailed := func(i int) {
+		group := req.Messages[i].MessageGroupID
+		if first, found := failedGroups[group]; fifo && (!found || i < first) {
+			failedGroups[group] = i
+		}
+	}
+
+	chunks, oversized := batchChunks(req.Messages)
+	if fifo {
+		chunks, oversized = fifoBatchChunks(req.Messages)
+	}
+	for _, i := range oversized {
+		results[i]["error"] = fmt.Sprintf("Mensagem excede o limite de %d bytes", maxSQSPayloadSize)
+		groupFailed(i)
+	}
+
+	for _, chunk := range chunks {
+		// O Id de cada entrada é a posição da mensagem na requisição
+		entries := make([]types.SendMessageBatchRequestEntry, 0, len(chunk))
+		sent := make([]int, 0, len(chunk))
+		for _, i := range chunk {
+			if first, found := failedGroups[req.Messages[i].MessageGroupID]; found && first < i {
+				results[i]["error"] = fmt.Sprintf("Não enviada: a mensagem %d do mesmo grupo falhou", first)
+				continue
+			}
+			sent = append(sent, i)
+			entries = append(entries, types.SendMessageBatchRequestEntry{
+				Id:                     aws.String(strconv.Itoa(i)),
+				MessageBody:            aws.String(req.Messages[i].text()),
+				MessageAttributes:      req.Messages[i].sqsMessageAttributes(),
+				DelaySeconds:           req.Messages[i].DelaySeconds,
+				MessageGroupId:         optionalString(req.Messages[i].MessageGroupID),
+				MessageDeduplicationId: optionalString(req.Messages[i].MessageDeduplicationID),
+			})
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		output, err := s.client.SendMessageBatch(context.TODO(), &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			if sqsErrorStatus(err) == http.StatusNotFound {
+				s.sqsError(c, name, "Erro ao enviar mensagens", err)
+				return
+			}
+			// Falhas da chamada inteira são registradas em cada entrada do lote
+			for _, i := range sent {
+				results[i]["error"] = err.Error()
+				groupFailed(i)
+			}
+			continue
+		}
+
+		for _, entry := range output.Successful {
+			if i, err := strconv.Atoi(aws.ToString(entry.Id)); err == nil {
+				results[i]["success"] = true
+				results[i]["message_id"] = aws.ToString(entry.MessageId)
+				if entry.SequenceNumber != nil {
+					results[i]["sequence_number"] = *entry.SequenceNumber
+				}
+			}
+		}
+		for _, entry := range output.Failed {
+			if i, err := strconv.Atoi(aws.ToString(entry.Id)); err == nil {
+				results[i]["error"] = aws.ToString(entry.Message)
+				groupFailed(i)
+			}
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result["success"] != true {
+			failed++
+		}
+	}
+
+	status := http.StatusOK
+	if failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"queue":     name,
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
+
+// receiveMessages chama ReceiveMessage até reunir max mensagens; apenas a primeira chamada faz long polling,
+// e a coleta termina quando a fila não retorna mais mensagens
+func (s *SQSController) receiveMessages(ctx context.Context, input sqs.ReceiveMessageInput, max int) ([]types.Message, error) {
+	messages := make([]types.Message, 0, max)
+	for len(messages) < max {
+		input.MaxNumberOfMessages = int32(min(max-len(messages), maxSQSBatchEntries))
+		output, err := s.client.ReceiveMessage(ctx, &input)
+		if err != nil {
+			// Mensagens já recebidas voltam à fila quando o visibility timeout expirar
+			return messages, err
+		}
+		if len(output.Messages) == 0 {
+			break
+		}
+		messages = append(messages, output.Messages...)
+		input.WaitTimeSeconds = 0
+	}
+	return messages, nil
+}
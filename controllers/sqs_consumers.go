@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"net/http"
+
+	"localstackdemo/sqsconsumer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SQSConsumerController expõe o estado dos consumidores SQS executados em segundo plano
+type SQSConsumerController struct {
+	consumers *sqsconsumer.Manager
+}
+
+func NewSQSConsumerController(consumers *sqsconsumer.Manager) *SQSConsumerController {
+	return &SQSConsumerController{
+		consumers: consumers,
+	}
+}
+
+// ListConsumers retorna, para cada fila consumida, as mensagens em processamento, processadas e com falha
+func (s *SQSConsumerController) ListConsumers(c *gin.Context) {
+	stats := s.consumers.Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"consumers": stats,
+		"count":     len(stats),
+	})
+}
@@ -2,112 +2,376 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"localstackdemo/config"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/gin-gonic/gin"
 )
 
+// sqsAPI reúne as operações do SQS usadas pelo controller; *sqs.Client a implementa e os testes usam uma fila em memória
+type sqsAPI interface {
+	sqs.ListQueuesAPIClient
+	CreateQueue(ctx context.Context, params *sqs.CreateQueueInput, optFns ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error)
+	DeleteQueue(ctx context.Context, params *sqs.DeleteQueueInput, optFns ...func(*sqs.Options)) (*sqs.DeleteQueueOutput, error)
+	GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+	SetQueueAttributes(ctx context.Context, params *sqs.SetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.SetQueueAttributesOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
 type SQSController struct {
-	client    *sqs.Client
-	queueURL  string
-	queueName string
+	client          sqsAPI
+	defaultQueue    string
+	maxReceiveCount int
+	redriveRate     int
+
+	// Contexto de vida do servidor; encerra os tails abertos e os monitores no desligamento
+	lifetime context.Context
+
+	// URLs das filas já resolvidas, indexadas pelo nome
+	mu        sync.RWMutex
+	queueURLs map[string]string
+
+	// Monitores de estatísticas ativos, indexados pelo nome da fila
+	monitorsMu sync.Mutex
+	monitors   map[string]*queueMonitor
 }
 
-func NewSQSController(cfg aws.Config) *SQSController {
-	client := sqs.NewFromConfig(cfg)
+// lifetime é cancelado no encerramento do servidor e interrompe os tails e monitores em andamento
+func NewSQSController(lifetime context.Context, cfg aws.Config, sqsCfg config.SQSConfig) *SQSController {
+	return newSQSController(lifetime, sqs.NewFromConfig(cfg), sqsCfg)
+}
+
+func newSQSController(lifetime context.Context, client sqsAPI, sqsCfg config.SQSConfig) *SQSController {
 	return &SQSController{
-		client:    client,
-		queueName: "demo-queue",
+		client:          client,
+		lifetime:        lifetime,
+		defaultQueue:    sqsCfg.DefaultQueue,
+		maxReceiveCount: sqsCfg.MaxReceiveCount,
+		redriveRate:     sqsCfg.RedriveRate,
+		queueURLs:       make(map[string]string),
+		monitors:        make(map[string]*queueMonitor),
 	}
 }
 
 func (s *SQSController) setupQueue() error {
 	// Criar fila se não existir
 	createQueueOutput, err := s.client.CreateQueue(context.TODO(), &sqs.CreateQueueInput{
-		QueueName: aws.String(s.queueName),
+		QueueName: aws.String(s.defaultQueue),
 	})
 	if err != nil {
 		return fmt.Errorf("erro ao criar fila SQS: %v", err)
 	}
 
-	s.queueURL = *createQueueOutput.QueueUrl
+	s.cacheQueueURL(s.defaultQueue, *createQueueOutput.QueueUrl)
 	return nil
 }
 
+func (s *SQSController) cacheQueueURL(name, queueURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueURLs[name] = queueURL
+}
+
+// forgetQueueURL descarta a URL em cache, usada quando a fila é removida
+func (s *SQSController) forgetQueueURL(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.queueURLs, name)
+}
+
+func (s *SQSController) cachedQueueURL(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	queueURL, ok := s.queueURLs[name]
+	return queueURL, ok
+}
+
+// queueURL retorna a URL da fila, consultando o SQS apenas na primeira vez
+func (s *SQSController) queueURL(ctx context.Context, name string) (string, error) {
+	if queueURL, ok := s.cachedQueueURL(name); ok {
+		return queueURL, nil
+	}
+
+	output, err := s.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
+		QueueName: aws.String(name),
+	})
+	if err != nil {
+		return "", err
+	}
+	s.cacheQueueURL(name, *output.QueueUrl)
+	return *output.QueueUrl, nil
+}
+
+// resolveQueue retorna o nome e a URL da fila da rota ou, na ausência dela, da fila padrão
+func (s *SQSController) resolveQueue(c *gin.Context) (string, string, bool) {
+	name := c.Param("name")
+	if name == "" {
+		name = s.defaultQueue
+
+		// Configurar fila padrão na primeira chamada
+		if _, ok := s.cachedQueueURL(name); !ok {
+			if err := s.setupQueue(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return "", "", false
+			}
+		}
+	}
+
+	queueURL, err := s.queueURL(context.TODO(), name)
+	if err != nil {
+		c.JSON(sqsErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao localizar fila %s: %v", name, err)})
+		return "", "", false
+	}
+	return name, queueURL, true
+}
+
+// sqsError responde com o erro do SQS, descartando a URL em cache se a fila não existir mais
+func (s *SQSController) sqsError(c *gin.Context, name string, message string, err error) {
+	status := sqsErrorStatus(err)
+	if status == http.StatusNotFound {
+		s.forgetQueueURL(name)
+	}
+	c.JSON(status, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+}
+
 type SendMessageRequest struct {
-	Message string `json:"message" binding:"required"`
+	// Corpo em texto; informe message ou body
+	Message string `json:"message"`
+	// Corpo em JSON, enviado compactado e devolvido decodificado no recebimento
+	Body json.RawMessage `json:"body"`
+	// Atributos tipados da mensagem, indexados pelo nome
+	Attributes map[string]MessageAttribute `json:"message_attributes"`
+	// Segundos até a mensagem ficar disponível (0 a 900); não suportado em filas FIFO
+	DelaySeconds int32 `json:"delay_seconds"`
+	// Obrigatório em filas FIFO: mensagens do mesmo grupo são entregues em ordem
+	MessageGroupID string `json:"message_group_id"`
+	// Só em filas FIFO; dispensável quando a fila usa deduplicação por conteúdo
+	MessageDeduplicationID string `json:"message_deduplication_id"`
 }
 
-func (s *SQSController) SendMessage(c *gin.Context) {
-	// Configurar fila na primeira chamada
-	if s.queueURL == "" {
-		if err := s.setupQueue(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+// isFIFOQueue indica se a fila é FIFO, o que o SQS exige no sufixo do nome
+func isFIFOQueue(name string) bool {
+	return strings.HasSuffix(name, ".fifo")
+}
+
+// Validate aplica os limites do SQS antes da chamada à API
+func (r SendMessageRequest) Validate(fifo bool) error {
+	if (r.Message == "") == !r.hasJSONBody() {
+		return fmt.Errorf("informe message ou body")
+	}
+	if r.DelaySeconds < 0 || r.DelaySeconds > maxDelaySeconds {
+		return fmt.Errorf("delay_seconds deve estar entre 0 e %d", maxDelaySeconds)
+	}
+	if fifo && r.DelaySeconds != 0 {
+		return fmt.Errorf("delay_seconds por mensagem não é aceito em filas FIFO")
+	}
+
+	attributes := len(r.Attributes)
+	if r.hasJSONBody() {
+		if _, found := r.Attributes[contentTypeAttribute]; found {
+			return fmt.Errorf("o atributo %s é reservado para mensagens com body", contentTypeAttribute)
+		}
+		attributes++
+	}
+	if attributes > maxMessageAttributes {
+		return fmt.Errorf("a mensagem aceita no máximo %d atributos", maxMessageAttributes)
+	}
+	names := make([]string, 0, len(r.Attributes))
+	for name := range r.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateMessageAttribute(name, r.Attributes[name]); err != nil {
+			return err
 		}
 	}
 
+	if fifo && r.MessageGroupID == "" {
+		return fmt.Errorf("message_group_id é obrigatório em filas FIFO")
+	}
+	if !fifo && r.MessageDeduplicationID != "" {
+		return fmt.Errorf("message_deduplication_id só é aceito em filas FIFO")
+	}
+	return nil
+}
+
+// optionalString converte strings vazias em nil para omitir o campo na chamada ao SQS
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+func (s *SQSController) SendMessage(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Mensagem é obrigatória"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Requisição inválida: %v", err)})
+		return
+	}
+	if err := req.Validate(isFIFOQueue(name)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if messageSize(req) > maxSQSPayloadSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Mensagem excede o limite de %d bytes", maxSQSPayloadSize)})
 		return
 	}
 
-	_, err := s.client.SendMessage(context.TODO(), &sqs.SendMessageInput{
-		QueueUrl:    aws.String(s.queueURL),
-		MessageBody: aws.String(req.Message),
+	output, err := s.client.SendMessage(context.TODO(), &sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(req.text()),
+		MessageAttributes:      req.sqsMessageAttributes(),
+		DelaySeconds:           req.DelaySeconds,
+		MessageGroupId:         optionalString(req.MessageGroupID),
+		MessageDeduplicationId: optionalString(req.MessageDeduplicationID),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao enviar mensagem: %v", err)})
+		s.sqsError(c, name, "Erro ao enviar mensagem", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Mensagem enviada com sucesso",
-	})
+	response := gin.H{
+		"message":    "Mensagem enviada com sucesso",
+		"queue":      name,
+		"message_id": aws.ToString(output.MessageId),
+	}
+	// Filas FIFO retornam o número de sequência, crescente dentro de cada grupo
+	if output.SequenceNumber != nil {
+		response["sequence_number"] = *output.SequenceNumber
+	}
+	c.JSON(http.StatusOK, response)
 }
 
-func (s *SQSController) ReceiveMessage(c *gin.Context) {
-	// Configurar fila na primeira chamada
-	if s.queueURL == "" {
-		if err := s.setupQueue(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+// boundedQueryParam lê um parâmetro inteiro da query dentro dos limites, usando o padrão quando ausente
+func boundedQueryParam(c *gin.Context, key string, min, max, fallback int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, nil
 	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s deve estar entre %d e %d", key, min, max)
+	}
+	return n, nil
+}
 
-	// Receber mensagem
-	result, err := s.client.ReceiveMessage(context.TODO(), &sqs.ReceiveMessageInput{
-		QueueUrl:            aws.String(s.queueURL),
-		MaxNumberOfMessages: 1,
-		WaitTimeSeconds:     20, // Long polling
-	})
+func (s *SQSController) ReceiveMessage(c *gin.Context) {
+	// O SDK não envia WaitTimeSeconds zerado: com wait_time=0 vale o ReceiveMessageWaitTimeSeconds da fila,
+	// que só retorna imediatamente quando a fila não foi configurada com long polling
+	waitTime, err := boundedQueryParam(c, "wait_time", 0, maxWaitTimeSeconds, maxWaitTimeSeconds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Sem visibility_timeout vale o configurado na fila; o mínimo é 1 porque o SDK também omite o zero, que
+	// acabaria aplicando o prazo da fila em vez de deixar a mensagem visível
+	visibilityTimeout, err := boundedQueryParam(c, "visibility_timeout", 1, maxVisibilityTimeout, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Com max_messages a resposta é uma lista; sem ele, uma única mensagem como antes
+	listMode := c.Query("max_messages") != ""
+	maxMessages, err := boundedQueryParam(c, "max_messages", 1, maxReceiveMessages, 1)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao receber mensagem: %v", err)})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if len(result.Messages) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Nenhuma mensagem na fila",
-		})
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
 		return
 	}
 
-	// Deletar mensagem após receber
-	_, err = s.client.DeleteMessage(context.TODO(), &sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(s.queueURL),
-		ReceiptHandle: result.Messages[0].ReceiptHandle,
-	})
+	// Por padrão a mensagem fica na fila até ser confirmada; auto_delete=true remove ao receber
+	autoDelete := c.Query("auto_delete") == "true"
+	input := sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(queueURL),
+		WaitTimeSeconds:       int32(waitTime),
+		VisibilityTimeout:     int32(visibilityTimeout),
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+		MessageAttributeNames: []string{"All"},
+	}
+
+	// O long polling é interrompido se o cliente desconectar; mensagens já recebidas
+	// voltam a ficar visíveis quando o visibility timeout expirar
+	ctx := c.Request.Context()
+	messages, err := s.receiveMessages(ctx, input, maxMessages)
+	if ctx.Err() != nil {
+		c.Abort()
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao deletar mensagem: %v", err)})
+		s.sqsError(c, name, "Erro ao receber mensagem", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": *result.Messages[0].Body,
-	})
+	responses := make([]gin.H, 0, len(messages))
+	receiptHandles := make([]string, 0, len(messages))
+	for _, message := range messages {
+		response := messageResponse(message)
+		response["deleted"] = false
+		responses = append(responses, response)
+		receiptHandles = append(receiptHandles, aws.ToString(message.ReceiptHandle))
+	}
+	// As mensagens recebidas são sempre retornadas: a remoção não pode ser desfeita, então uma falha
+	// em um dos lotes é informada por mensagem (com o receipt handle para confirmar depois) e não como erro
+	status := http.StatusOK
+	if autoDelete && len(messages) > 0 {
+		failures, _ := s.deleteMessageBatch(ctx, queueURL, receiptHandles)
+		if ctx.Err() != nil {
+			c.Abort()
+			return
+		}
+		for i, response := range responses {
+			if failure, failed := failures[i]; failed {
+				response["delete_error"] = failure
+				status = http.StatusMultiStatus
+				continue
+			}
+			// O receipt handle não serve mais para confirmar mensagens já removidas
+			response["deleted"] = true
+			delete(response, "receipt_handle")
+		}
+	}
+
+	if listMode {
+		c.JSON(status, gin.H{
+			"queue":    name,
+			"messages": responses,
+			"count":    len(responses),
+		})
+		return
+	}
+
+	if len(responses) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Nenhuma mensagem na fila",
+		})
+		return
+	}
+	c.JSON(status, responses[0])
 }
@@ -0,0 +1,477 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Limites do maxReceiveCount aceitos pelo SQS e da taxa de redrive
+const (
+	maxMaxReceiveCount = 1000
+	maxRedriveRate     = 100
+)
+
+// Tempo que as mensagens da DLQ ficam ocultas enquanto são inspecionadas ou devolvidas
+const deadLetterVisibilityTimeout = 60
+
+var errNoDeadLetterQueue = errors.New("fila sem DLQ configurada")
+
+// redrivePolicy é o atributo RedrivePolicy da fila de origem; o SQS aceita maxReceiveCount como número ou string
+type redrivePolicy struct {
+	DeadLetterTargetArn string      `json:"deadLetterTargetArn"`
+	MaxReceiveCount     json.Number `json:"maxReceiveCount"`
+}
+
+// queueNameFromARN extrai o nome da fila do ARN (arn:aws:sqs:<região>:<conta>:<nome>)
+func queueNameFromARN(arn string) string {
+	return arn[strings.LastIndex(arn, ":")+1:]
+}
+
+// defaultDeadLetterQueueName gera o nome da DLQ a partir da fila de origem, mantendo o sufixo .fifo
+func defaultDeadLetterQueueName(name string) string {
+	if isFIFOQueue(name) {
+		return strings.TrimSuffix(name, ".fifo") + "-dlq.fifo"
+	}
+	return name + "-dlq"
+}
+
+// parseRedrivePolicy extrai a RedrivePolicy dos atributos da fila
+func parseRedrivePolicy(attributes map[string]string) (redrivePolicy, error) {
+	var policy redrivePolicy
+	raw := attributes[string(types.QueueAttributeNameRedrivePolicy)]
+	if raw == "" {
+		return policy, errNoDeadLetterQueue
+	}
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		return policy, fmt.Errorf("RedrivePolicy inválida: %v", err)
+	}
+	return policy, nil
+}
+
+// deadLetterQueue lê a RedrivePolicy da fila e retorna o nome e a URL da DLQ
+func (s *SQSController) deadLetterQueue(ctx context.Context, queueURL string) (string, string, redrivePolicy, error) {
+	output, err := s.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+	})
+	if err != nil {
+		return "", "", redrivePolicy{}, err
+	}
+	policy, err := parseRedrivePolicy(output.Attributes)
+	if err != nil {
+		return "", "", policy, err
+	}
+
+	dlqName := queueNameFromARN(policy.DeadLetterTargetArn)
+	dlqURL, err := s.queueURL(ctx, dlqName)
+	return dlqName, dlqURL, policy, err
+}
+
+// deadLetterQueueError responde 404 quando a fila não tem DLQ e delega os demais erros ao sqsError
+func (s *SQSController) deadLetterQueueError(c *gin.Context, name string, err error) {
+	if errors.Is(err, errNoDeadLetterQueue) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Fila %s sem DLQ configurada", name)})
+		return
+	}
+	s.sqsError(c, name, "Erro ao consultar DLQ", err)
+}
+
+type SetDeadLetterQueueRequest struct {
+	// Nome da DLQ, criada se não existir; o padrão é <fila>-dlq
+	DeadLetterQueue string `json:"dlq_name"`
+	// Recebimentos sem confirmação antes de a mensagem ser movida para a DLQ
+	MaxReceiveCount int `json:"max_receive_count"`
+}
+
+// SetDeadLetterQueue configura a DLQ da fila através da RedrivePolicy
+func (s *SQSController) SetDeadLetterQueue(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	var req SetDeadLetterQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Requisição inválida: %v", err)})
+		return
+	}
+	if req.DeadLetterQueue == "" {
+		req.DeadLetterQueue = defaultDeadLetterQueueName(name)
+	}
+	if req.MaxReceiveCount == 0 {
+		req.MaxReceiveCount = s.maxReceiveCount
+	}
+	if req.MaxReceiveCount < 1 || req.MaxReceiveCount > maxMaxReceiveCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("max_receive_count deve estar entre 1 e %d", maxMaxReceiveCount)})
+		return
+	}
+	// O SQS exige que a DLQ seja do mesmo tipo (padrão ou FIFO) da fila de origem
+	if isFIFOQueue(req.DeadLetterQueue) != isFIFOQueue(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A DLQ deve ser FIFO se, e somente se, a fila de origem for FIFO"})
+		return
+	}
+	if req.DeadLetterQueue == name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A DLQ deve ser diferente da fila de origem"})
+		return
+	}
+
+	ctx := context.TODO()
+	dlqURL, err := s.queueURL(ctx, req.DeadLetterQueue)
+	if err != nil && sqsErrorStatus(err) == http.StatusNotFound {
+		input := &sqs.CreateQueueInput{QueueName: aws.String(req.DeadLetterQueue)}
+		if isFIFOQueue(req.DeadLetterQueue) {
+			input.Attributes = map[string]string{string(types.QueueAttributeNameFifoQueue): "true"}
+		}
+		var output *sqs.CreateQueueOutput
+		if output, err = s.client.CreateQueue(ctx, input); err == nil {
+			dlqURL = *output.QueueUrl
+			s.cacheQueueURL(req.DeadLetterQueue, dlqURL)
+		}
+	}
+	if err != nil {
+		c.JSON(sqsErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao preparar DLQ %s: %v", req.DeadLetterQueue, err)})
+		return
+	}
+
+	attributes, err := s.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(dlqURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameQueueArn},
+	})
+	if err != nil {
+		s.sqsError(c, req.DeadLetterQueue, "Erro ao consultar DLQ", err)
+		return
+	}
+	dlqArn := attributes.Attributes[string(types.QueueAttributeNameQueueArn)]
+
+	policy, _ := json.Marshal(redrivePolicy{
+		DeadLetterTargetArn: dlqArn,
+		MaxReceiveCount:     json.Number(strconv.Itoa(req.MaxReceiveCount)),
+	})
+	_, err = s.client.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL),
+		Attributes: map[string]string{
+			string(types.QueueAttributeNameRedrivePolicy): string(policy),
+		},
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao configurar DLQ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "DLQ configurada com sucesso",
+		"queue":             name,
+		"dlq":               req.DeadLetterQueue,
+		"dlq_arn":           dlqArn,
+		"max_receive_count": req.MaxReceiveCount,
+	})
+}
+
+func (s *SQSController) GetDeadLetterQueue(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	dlqName, dlqURL, policy, err := s.deadLetterQueue(context.TODO(), queueURL)
+	if err != nil {
+		s.deadLetterQueueError(c, name, err)
+		return
+	}
+
+	maxReceiveCount, _ := policy.MaxReceiveCount.Int64()
+	c.JSON(http.StatusOK, gin.H{
+		"queue":             name,
+		"dlq":               dlqName,
+		"dlq_url":           dlqURL,
+		"dlq_arn":           policy.DeadLetterTargetArn,
+		"max_receive_count": maxReceiveCount,
+	})
+}
+
+// RemoveDeadLetterQueue remove a RedrivePolicy da fila; a DLQ e suas mensagens são mantidas
+func (s *SQSController) RemoveDeadLetterQueue(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	_, err := s.client.SetQueueAttributes(context.TODO(), &sqs.SetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL),
+		Attributes: map[string]string{
+			string(types.QueueAttributeNameRedrivePolicy): "",
+		},
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao remover DLQ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "DLQ removida da fila com sucesso",
+		"queue":   name,
+	})
+}
+
+// deadLetterResponse monta a resposta de uma mensagem da DLQ com o número de recebimentos e a fila de origem
+func deadLetterResponse(message types.Message) gin.H {
+	response := messageResponse(message)
+	response["receive_count"], _ = strconv.Atoi(message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+	if source := message.Attributes[string(types.MessageSystemAttributeNameDeadLetterQueueSourceArn)]; source != "" {
+		response["source_queue"] = queueNameFromARN(source)
+	}
+	return response
+}
+
+// releaseMessages torna as mensagens visíveis novamente. Usa ChangeMessageVisibility por mensagem porque o SDK
+// omite VisibilityTimeout igual a zero nas entradas de ChangeMessageVisibilityBatch; retorna erro se alguma
+// mensagem não for liberada, já que ela ficaria oculta até o visibility timeout expirar
+func (s *SQSController) releaseMessages(ctx context.Context, queueURL string, messages []types.Message) error {
+	failed := 0
+	var firstErr error
+	for _, message := range messages {
+		_, err := s.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(queueURL),
+			ReceiptHandle:     message.ReceiptHandle,
+			VisibilityTimeout: 0,
+		})
+		if err != nil {
+			failed++
+			firstErr = cmp.Or(firstErr, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d de %d mensagens não foram liberadas: %w", failed, len(messages), firstErr)
+	}
+	return nil
+}
+
+// ListDeadLetters retorna as mensagens da DLQ sem removê-las; cada inspeção conta como um recebimento
+func (s *SQSController) ListDeadLetters(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	maxMessages, err := boundedQueryParam(c, "max_messages", 1, maxReceiveMessages, maxSQSBatchEntries)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.TODO()
+	dlqName, dlqURL, _, err := s.deadLetterQueue(ctx, queueURL)
+	if err != nil {
+		s.deadLetterQueueError(c, name, err)
+		return
+	}
+
+	messages, err := s.receiveMessages(ctx, sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(dlqURL),
+		VisibilityTimeout:     deadLetterVisibilityTimeout,
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+		MessageAttributeNames: []string{"All"},
+	}, maxMessages)
+	if err != nil {
+		s.sqsError(c, dlqName, "Erro ao ler DLQ", err)
+		return
+	}
+	// As mensagens voltam a ficar visíveis na DLQ logo após a leitura
+	if err := s.releaseMessages(ctx, dlqURL, messages); err != nil {
+		s.sqsError(c, dlqName, "Erro ao liberar mensagens da DLQ", err)
+		return
+	}
+
+	responses := make([]gin.H, 0, len(messages))
+	for _, message := range messages {
+		responses = append(responses, deadLetterResponse(message))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"queue":    name,
+		"dlq":      dlqName,
+		"messages": responses,
+		"count":    len(responses),
+	})
+}
+
+type RedriveRequest struct {
+	// IDs das mensagens a devolver; vazio devolve todas
+	MessageIDs []string `json:"message_ids"`
+	// Quantidade máxima de mensagens devolvidas; zero não limita
+	MaxMessages int `json:"max_messages"`
+	// Mensagens por segundo; zero usa SQS_REDRIVE_RATE
+	Rate int `json:"rate"`
+}
+
+// moveMessage reenvia a mensagem da DLQ para a fila de origem e só então a remove da DLQ
+func (s *SQSController) moveMessage(ctx context.Context, dlqURL, queueURL string, fifo bool, message types.Message) error {
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		MessageBody:       message.Body,
+		MessageAttributes: message.MessageAttributes,
+	}
+	if fifo {
+		// Cada devolução usa uma chave de deduplicação nova: se a mensagem voltar à DLQ e for devolvida de novo
+		// em menos de 5 minutos, o SQS não pode descartá-la como duplicata, senão ela seria apagada da DLQ sem
+		// nenhuma cópia. As retentativas automáticas do SDK reutilizam a mesma chave
+		input.MessageGroupId = aws.String(message.Attributes[string(types.MessageSystemAttributeNameMessageGroupId)])
+		input.MessageDeduplicationId = aws.String(aws.ToString(message.MessageId) + "-" + uuid.New().String())
+	}
+	if _, err := s.client.SendMessage(ctx, input); err != nil {
+		return err
+	}
+
+	_, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(dlqURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	if err != nil {
+		return fmt.Errorf("mensagem reenviada, mas não removida da DLQ: %v", err)
+	}
+	return nil
+}
+
+// RedriveDeadLetters devolve mensagens da DLQ à fila de origem, todas ou apenas as selecionadas,
+// respeitando a taxa de mensagens por segundo
+func (s *SQSController) RedriveDeadLetters(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	var req RedriveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Requisição inválida: %v", err)})
+		return
+	}
+	if req.Rate == 0 {
+		req.Rate = s.redriveRate
+	}
+	if req.Rate < 1 || req.Rate > maxRedriveRate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rate deve estar entre 1 e %d mensagens por segundo", maxRedriveRate)})
+		return
+	}
+	if req.MaxMessages < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_messages não pode ser negativo"})
+		return
+	}
+
+	// O redrive pode levar vários segundos; o contexto da requisição o interrompe se o cliente desconectar
+	ctx := c.Request.Context()
+	dlqName, dlqURL, _, err := s.deadLetterQueue(ctx, queueURL)
+	if err != nil {
+		s.deadLetterQueueError(c, name, err)
+		return
+	}
+
+	var selected map[string]bool
+	if len(req.MessageIDs) > 0 {
+		selected = make(map[string]bool, len(req.MessageIDs))
+		for _, id := range req.MessageIDs {
+			selected[id] = true
+		}
+	}
+
+	moved := make([]string, 0)
+	failures := make([]gin.H, 0)
+	// Mensagens recebidas mas não devolvidas, liberadas ao final
+	skipped := make([]types.Message, 0)
+	seen := make(map[string]bool)
+
+	limiter := time.NewTicker(time.Second / time.Duration(req.Rate))
+	defer limiter.Stop()
+
+	done := func() bool {
+		return (req.MaxMessages > 0 && len(moved)+len(failures) >= req.MaxMessages) ||
+			(selected != nil && len(selected) == 0) ||
+			ctx.Err() != nil
+	}
+
+receive:
+	for !done() {
+		output, err := s.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(dlqURL),
+			MaxNumberOfMessages:   maxSQSBatchEntries,
+			VisibilityTimeout:     deadLetterVisibilityTimeout,
+			AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			if len(moved)+len(failures) == 0 {
+				s.sqsError(c, dlqName, "Erro ao ler DLQ", err)
+				return
+			}
+			failures = append(failures, gin.H{"error": fmt.Sprintf("Erro ao ler DLQ: %v", err)})
+			break
+		}
+		if len(output.Messages) == 0 {
+			break
+		}
+
+		for i, message := range output.Messages {
+			id := aws.ToString(message.MessageId)
+			// Uma mensagem repetida indica que a DLQ inteira já foi percorrida
+			if seen[id] {
+				skipped = append(skipped, output.Messages[i:]...)
+				break receive
+			}
+			seen[id] = true
+
+			if done() || (selected != nil && !selected[id]) {
+				skipped = append(skipped, message)
+				continue
+			}
+			delete(selected, id)
+
+			select {
+			case <-ctx.Done():
+				skipped = append(skipped, output.Messages[i:]...)
+				break receive
+			case <-limiter.C:
+			}
+
+			if err := s.moveMessage(ctx, dlqURL, queueURL, isFIFOQueue(name), message); err != nil {
+				failures = append(failures, gin.H{"message_id": id, "error": err.Error()})
+				continue
+			}
+			moved = append(moved, id)
+		}
+	}
+
+	// A liberação não depende do cliente: sem ela as mensagens ficariam ocultas até o timeout expirar
+	if err := s.releaseMessages(context.WithoutCancel(ctx), dlqURL, skipped); err != nil {
+		failures = append(failures, gin.H{"error": fmt.Sprintf("Erro ao liberar mensagens não devolvidas: %v", err)})
+	}
+
+	notFound := make([]string, 0, len(selected))
+	for id := range selected {
+		notFound = append(notFound, id)
+	}
+
+	status := http.StatusOK
+	if len(failures) > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"queue":     name,
+		"dlq":       dlqName,
+		"moved":     moved,
+		"failed":    failures,
+		"not_found": notFound,
+		"rate":      req.Rate,
+	})
+}
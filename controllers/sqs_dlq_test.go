@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"localstackdemo/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeQueues encaminha cada chamada para a fila em memória da URL informada e simula a RedrivePolicy
+type fakeQueues struct {
+	// Operações não usadas pelos testes não são implementadas
+	sqsAPI
+
+	queues map[string]*fakeFIFOQueue
+	// Nome da DLQ de cada fila de origem
+	deadLetters map[string]string
+}
+
+func newFakeQueues(queues ...*fakeFIFOQueue) *fakeQueues {
+	f := &fakeQueues{queues: make(map[string]*fakeFIFOQueue), deadLetters: make(map[string]string)}
+	for _, queue := range queues {
+		f.queues[queue.url()] = queue
+	}
+	return f
+}
+
+func (f *fakeQueues) queue(url *string) (*fakeFIFOQueue, error) {
+	queue, ok := f.queues[aws.ToString(url)]
+	if !ok {
+		return nil, &types.QueueDoesNotExist{Message: aws.String("fila inexistente")}
+	}
+	return queue, nil
+}
+
+func (f *fakeQueues) GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	for _, queue := range f.queues {
+		if queue.name == aws.ToString(params.QueueName) {
+			return queue.GetQueueUrl(ctx, params, optFns...)
+		}
+	}
+	return nil, &types.QueueDoesNotExist{Message: aws.String("fila inexistente")}
+}
+
+func (f *fakeQueues) GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	queue, err := f.queue(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	attributes := map[string]string{}
+	if dlq, ok := f.deadLetters[queue.name]; ok {
+		policy, _ := json.Marshal(redrivePolicy{
+			DeadLetterTargetArn: "arn:aws:sqs:us-east-1:000000000000:" + dlq,
+			MaxReceiveCount:     "5",
+		})
+		attributes[string(types.QueueAttributeNameRedrivePolicy)] = string(policy)
+	}
+	return &sqs.GetQueueAttributesOutput{Attributes: attributes}, nil
+}
+
+func (f *fakeQueues) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	queue, err := f.queue(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	return queue.SendMessage(ctx, params, optFns...)
+}
+
+func (f *fakeQueues) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	queue, err := f.queue(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	return queue.ReceiveMessage(ctx, params, optFns...)
+}
+
+func (f *fakeQueues) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	queue, err := f.queue(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	return queue.DeleteMessage(ctx, params, optFns...)
+}
+
+func (f *fakeQueues) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	queue, err := f.queue(params.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	return queue.ChangeMessageVisibility(ctx, params, optFns...)
+}
+
+// moveToDeadLetter simula o SQS movendo a primeira mensagem da origem para a DLQ depois de maxReceiveCount
+// recebimentos, chegando lá com o ID informado
+func moveToDeadLetter(source, dlq *fakeFIFOQueue, id string) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	dlq.mu.Lock()
+	defer dlq.mu.Unlock()
+
+	message := source.messages[0]
+	source.messages = source.messages[1:]
+	dlq.messages = append(dlq.messages, &fakeMessage{id: id, body: message.body, group: message.group})
+}
+
+func TestRedriveSameMessageTwice(t *testing.T) {
+	source := newFakeFIFOQueue("pedidos.fifo")
+	dlq := newFakeFIFOQueue("pedidos-dlq.fifo")
+	dlq.messages = append(dlq.messages, &fakeMessage{id: "msg-original", body: "pedido 1", group: "cliente-1"})
+	queues := newFakeQueues(source, dlq)
+	queues.deadLetters[source.name] = dlq.name
+
+	gin.SetMode(gin.TestMode)
+	controller := newSQSController(context.Background(), queues, config.SQSConfig{DefaultQueue: source.name})
+	r := gin.New()
+	r.POST("/sqs/queues/:name/dlq/redrive", controller.RedriveDeadLetters)
+
+	redrive := func() {
+		t.Helper()
+		status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/pedidos.fifo/dlq/redrive", map[string]any{"rate": maxRedriveRate})
+		if status != http.StatusOK {
+			t.Fatalf("redrive: status %d, resposta %v", status, response)
+		}
+		if moved := response["moved"].([]any); len(moved) != 1 {
+			t.Fatalf("redrive: moved = %v, esperado 1 mensagem", moved)
+		}
+		if source.pending() != 1 || dlq.pending() != 0 {
+			t.Fatalf("depois do redrive: origem com %d e DLQ com %d mensagens, esperado 1 e 0", source.pending(), dlq.pending())
+		}
+	}
+
+	redrive()
+
+	// A mensagem devolvida falha de novo e volta à DLQ com o mesmo ID antes de a janela de deduplicação expirar;
+	// o segundo redrive não pode ser descartado como duplicata, senão ela seria apagada da DLQ sem cópia
+	moveToDeadLetter(source, dlq, "msg-original")
+	redrive()
+}
+
+func TestReleaseMessagesReportsFailures(t *testing.T) {
+	queue := newFakeFIFOQueue("eventos.fifo")
+	for _, group := range []string{"a", "b", "c"} {
+		queue.messages = append(queue.messages, &fakeMessage{id: "msg-" + group, body: group, group: group})
+	}
+	controller := newSQSController(context.Background(), queue, config.SQSConfig{DefaultQueue: queue.name})
+
+	output, err := queue.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{QueueUrl: aws.String(queue.url()), MaxNumberOfMessages: 10})
+	if err != nil || len(output.Messages) != 3 {
+		t.Fatalf("recebimento: %v, %d mensagens", err, len(output.Messages))
+	}
+	// O receipt handle da segunda mensagem expirou; as outras ainda devem ser liberadas
+	output.Messages[1].ReceiptHandle = aws.String("receipt-expirado")
+
+	err = controller.releaseMessages(context.Background(), queue.url(), output.Messages)
+	if err == nil || !strings.Contains(err.Error(), "1 de 3") {
+		t.Fatalf("releaseMessages = %v, esperado erro para 1 de 3 mensagens", err)
+	}
+	if sqsErrorStatus(err) != http.StatusBadRequest {
+		t.Errorf("status do erro = %d, esperado o do erro original do SQS", sqsErrorStatus(err))
+	}
+
+	visible, _ := queue.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{QueueUrl: aws.String(queue.url()), MaxNumberOfMessages: 10})
+	if len(visible.Messages) != 2 {
+		t.Errorf("%d mensagens visíveis depois da liberação, esperado 2", len(visible.Messages))
+	}
+}
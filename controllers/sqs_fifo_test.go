@@ -0,0 +1,588 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"localstackdemo/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeFIFOQueue simula as regras de entrega de uma fila FIFO do SQS: mensagens de um grupo só são
+// entregues em ordem e nenhuma é entregue enquanto outra do mesmo grupo estiver em processamento
+type fakeFIFOQueue struct {
+	// Operações não usadas pelos testes não são implementadas
+	sqsAPI
+
+	mu                   sync.Mutex
+	name                 string
+	contentDeduplication bool
+	// Corpos que a fila rejeita, para simular falhas por entrada no envio em lote
+	rejectBodies map[string]bool
+
+	messages     []*fakeMessage
+	deduplicated map[string]*fakeMessage
+	sequence     int64
+	receipts     int
+}
+
+type fakeMessage struct {
+	id       string
+	body     string
+	group    string
+	sequence string
+	receipt  string
+}
+
+func newFakeFIFOQueue(name string) *fakeFIFOQueue {
+	return &fakeFIFOQueue{
+		name:         name,
+		rejectBodies: make(map[string]bool),
+		deduplicated: make(map[string]*fakeMessage),
+	}
+}
+
+func (q *fakeFIFOQueue) url() string {
+	return "http://sqs.local/000000000000/" + q.name
+}
+
+func (q *fakeFIFOQueue) GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	if aws.ToString(params.QueueName) != q.name {
+		return nil, &types.QueueDoesNotExist{Message: aws.String("fila inexistente")}
+	}
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(q.url())}, nil
+}
+
+// enqueue aplica a deduplicação e adiciona a mensagem ao fim da fila; q.mu deve estar travado
+func (q *fakeFIFOQueue) enqueue(body string, group, deduplicationID *string) (*fakeMessage, error) {
+	if group == nil {
+		return nil, fmt.Errorf("MessageGroupId é obrigatório")
+	}
+	if deduplicationID == nil {
+		if !q.contentDeduplication {
+			return nil, fmt.Errorf("MessageDeduplicationId é obrigatório")
+		}
+		sum := sha256.Sum256([]byte(body))
+		deduplicationID = aws.String(hex.EncodeToString(sum[:]))
+	}
+	if message, found := q.deduplicated[*deduplicationID]; found {
+		return message, nil
+	}
+
+	q.sequence++
+	message := &fakeMessage{
+		id:       fmt.Sprintf("msg-%d", q.sequence),
+		body:     body,
+		group:    *group,
+		sequence: fmt.Sprintf("%020d", q.sequence),
+	}
+	q.messages = append(q.messages, message)
+	q.deduplicated[*deduplicationID] = message
+	return message, nil
+}
+
+func (q *fakeFIFOQueue) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	message, err := q.enqueue(aws.ToString(params.MessageBody), params.MessageGroupId, params.MessageDeduplicationId)
+	if err != nil {
+		return nil, err
+	}
+	return &sqs.SendMessageOutput{MessageId: aws.String(message.id), SequenceNumber: aws.String(message.sequence)}, nil
+}
+
+func (q *fakeFIFOQueue) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	output := &sqs.SendMessageBatchOutput{}
+	for _, entry := range params.Entries {
+		body := aws.ToString(entry.MessageBody)
+		if q.rejectBodies[body] {
+			output.Failed = append(output.Failed, types.BatchResultErrorEntry{Id: entry.Id, Message: aws.String("rejeitada")})
+			continue
+		}
+		message, err := q.enqueue(body, entry.MessageGroupId, entry.MessageDeduplicationId)
+		if err != nil {
+			output.Failed = append(output.Failed, types.BatchResultErrorEntry{Id: entry.Id, Message: aws.String(err.Error())})
+			continue
+		}
+		output.Successful = append(output.Successful, types.SendMessageBatchResultEntry{
+			Id:             entry.Id,
+			MessageId:      aws.String(message.id),
+			SequenceNumber: aws.String(message.sequence),
+		})
+	}
+	return output, nil
+}
+
+func (q *fakeFIFOQueue) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Grupos com mensagem em processamento ficam bloqueados até ela ser removida ou devolvida
+	blocked := make(map[string]bool)
+	for _, message := range q.messages {
+		if message.receipt != "" {
+			blocked[message.group] = true
+		}
+	}
+
+	output := &sqs.ReceiveMessageOutput{}
+	for _, message := range q.messages {
+		if len(output.Messages) == int(params.MaxNumberOfMessages) {
+			break
+		}
+		if blocked[message.group] || message.receipt != "" {
+			continue
+		}
+		q.receipts++
+		message.receipt = "receipt-" + strconv.Itoa(q.receipts)
+		output.Messages = append(output.Messages, types.Message{
+			MessageId:     aws.String(message.id),
+			ReceiptHandle: aws.String(message.receipt),
+			Body:          aws.String(message.body),
+			Attributes: map[string]string{
+				string(types.MessageSystemAttributeNameMessageGroupId): message.group,
+				string(types.MessageSystemAttributeNameSequenceNumber): message.sequence,
+			},
+		})
+	}
+	return output, nil
+}
+
+// release remove a mensagem do receipt handle ou a devolve à fila; q.mu deve estar travado
+func (q *fakeFIFOQueue) release(receipt string, remove bool) error {
+	for i, message := range q.messages {
+		if message.receipt != receipt {
+			continue
+		}
+		if remove {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+		} else {
+			message.receipt = ""
+		}
+		return nil
+	}
+	return &types.ReceiptHandleIsInvalid{Message: aws.String("receipt handle inválido")}
+}
+
+func (q *fakeFIFOQueue) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return &sqs.DeleteMessageOutput{}, q.release(aws.ToString(params.ReceiptHandle), true)
+}
+
+func (q *fakeFIFOQueue) DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	output := &sqs.DeleteMessageBatchOutput{}
+	for _, entry := range params.Entries {
+		if err := q.release(aws.ToString(entry.ReceiptHandle), true); err != nil {
+			output.Failed = append(output.Failed, types.BatchResultErrorEntry{Id: entry.Id, Message: aws.String(err.Error())})
+			continue
+		}
+		output.Successful = append(output.Successful, types.DeleteMessageBatchResultEntry{Id: entry.Id})
+	}
+	return output, nil
+}
+
+func (q *fakeFIFOQueue) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if params.VisibilityTimeout != 0 {
+		return &sqs.ChangeMessageVisibilityOutput{}, nil
+	}
+	return &sqs.ChangeMessageVisibilityOutput{}, q.release(aws.ToString(params.ReceiptHandle), false)
+}
+
+func (q *fakeFIFOQueue) pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages)
+}
+
+func newFIFOTestRouter(queue *fakeFIFOQueue) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := newSQSController(context.Background(), queue, config.SQSConfig{DefaultQueue: queue.name})
+
+	r := gin.New()
+	r.POST("/sqs/queues/:name/messages", controller.SendMessage)
+	r.GET("/sqs/queues/:name/messages", controller.ReceiveMessage)
+	r.POST("/sqs/queues/:name/messages/batch", controller.SendMessageBatch)
+	r.POST("/sqs/queues/:name/messages/ack", controller.AckMessage)
+	r.POST("/sqs/queues/:name/messages/nack", controller.NackMessage)
+	return r
+}
+
+func doJSON(t *testing.T, r http.Handler, method, path string, body any) (int, map[string]any) {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s %s: resposta inválida %q: %v", method, path, w.Body.String(), err)
+	}
+	return w.Code, response
+}
+
+func TestSendMessageRequestValidateFIFO(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SendMessageRequest
+		fifo    bool
+		wantErr bool
+	}{
+		{"grupo em fila FIFO", SendMessageRequest{Message: "a", MessageGroupID: "g"}, true, false},
+		{"grupo e deduplicação em fila FIFO", SendMessageRequest{Message: "a", MessageGroupID: "g", MessageDeduplicationID: "d"}, true, false},
+		{"sem grupo em fila FIFO", SendMessageRequest{Message: "a"}, true, true},
+		{"atraso em fila FIFO", SendMessageRequest{Message: "a", MessageGroupID: "g", DelaySeconds: 5}, true, true},
+		{"deduplicação em fila padrão", SendMessageRequest{Message: "a", MessageDeduplicationID: "d"}, false, true},
+		{"fila padrão", SendMessageRequest{Message: "a", DelaySeconds: 5}, false, false},
+	}
+	for _, tt := range tests {
+		if err := tt.req.Validate(tt.fifo); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, erro esperado: %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFIFODeduplication(t *testing.T) {
+	queue := newFakeFIFOQueue("pedidos.fifo")
+	r := newFIFOTestRouter(queue)
+
+	first := map[string]any{"message": "pedido 1", "message_group_id": "cliente-1", "message_deduplication_id": "pedido-1"}
+	status, sent := doJSON(t, r, http.MethodPost, "/sqs/queues/pedidos.fifo/messages", first)
+	if status != http.StatusOK || sent["sequence_number"] == nil {
+		t.Fatalf("envio: status %d, resposta %v", status, sent)
+	}
+	_, again := doJSON(t, r, http.MethodPost, "/sqs/queues/pedidos.fifo/messages", first)
+	if again["message_id"] != sent["message_id"] || again["sequence_number"] != sent["sequence_number"] {
+		t.Errorf("reenvio com o mesmo message_deduplication_id gerou outra mensagem: %v, %v", sent, again)
+	}
+
+	queue.contentDeduplication = true
+	byContent := map[string]any{"message": "pedido 2", "message_group_id": "cliente-1"}
+	_, sent = doJSON(t, r, http.MethodPost, "/sqs/queues/pedidos.fifo/messages", byContent)
+	_, again = doJSON(t, r, http.MethodPost, "/sqs/queues/pedidos.fifo/messages", byContent)
+	if again["message_id"] != sent["message_id"] {
+		t.Errorf("deduplicação por conteúdo gerou outra mensagem: %v, %v", sent, again)
+	}
+
+	if pending := queue.pending(); pending != 2 {
+		t.Errorf("fila com %d mensagens, esperado 2", pending)
+	}
+
+	status, rejected := doJSON(t, r, http.MethodPost, "/sqs/queues/pedidos.fifo/messages", map[string]any{"message": "sem grupo"})
+	if status != http.StatusBadRequest {
+		t.Errorf("envio sem message_group_id: status %d, resposta %v", status, rejected)
+	}
+}
+
+// TestFIFOPerGroupOrdering envia mensagens de vários grupos intercaladas, por envio simples e em lote,
+// e as processa com consumidores concorrentes; cada grupo deve ser processado exatamente na ordem de envio
+func TestFIFOPerGroupOrdering(t *testing.T) {
+	const (
+		groups           = 5
+		messagesPerGroup = 40
+		consumers        = 8
+	)
+	queue := newFakeFIFOQueue("eventos.fifo")
+	queue.contentDeduplication = true
+	r := newFIFOTestRouter(queue)
+
+	batch := make([]map[string]any, 0)
+	for n := 0; n < messagesPerGroup; n++ {
+		for g := 0; g < groups; g++ {
+			message := map[string]any{
+				"message":          fmt.Sprintf("grupo-%d:%d", g, n),
+				"message_group_id": fmt.Sprintf("grupo-%d", g),
+			}
+			// Parte das mensagens vai por envio simples, depois de enviar o lote acumulado para manter a ordem
+			if n%7 == 3 {
+				if len(batch) > 0 {
+					if status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/batch", map[string]any{"messages": batch}); status != http.StatusOK {
+						t.Fatalf("envio em lote: status %d, resposta %v", status, response)
+					}
+					batch = batch[:0]
+				}
+				if status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages", message); status != http.StatusOK {
+					t.Fatalf("envio: status %d, resposta %v", status, response)
+				}
+				continue
+			}
+			batch = append(batch, message)
+		}
+	}
+	if status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/batch", map[string]any{"messages": batch}); status != http.StatusOK {
+		t.Fatalf("envio em lote: status %d, resposta %v", status, response)
+	}
+
+	var (
+		mu        sync.Mutex
+		processed = make(map[string][]int)
+		total     int
+		inFlight  = make(map[string]bool)
+		parallel  int
+	)
+	process := func(message map[string]any) error {
+		group, index, found := strings.Cut(message["message"].(string), ":")
+		if !found {
+			return fmt.Errorf("corpo inesperado: %v", message["message"])
+		}
+		n, _ := strconv.Atoi(index)
+		attributes := message["attributes"].(map[string]any)
+		if attributes[string(types.MessageSystemAttributeNameMessageGroupId)] != group {
+			return fmt.Errorf("mensagem %v sem o MessageGroupId de envio", message)
+		}
+
+		mu.Lock()
+		if inFlight[group] {
+			mu.Unlock()
+			return fmt.Errorf("grupo %s entregue a dois consumidores ao mesmo tempo", group)
+		}
+		inFlight[group] = true
+		parallel = max(parallel, len(inFlight))
+		processed[group] = append(processed[group], n)
+		total++
+		mu.Unlock()
+
+		time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+
+		mu.Lock()
+		delete(inFlight, group)
+		mu.Unlock()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Cada consumidor reporta no máximo um erro e interrompe os demais
+	errs := make(chan error, consumers)
+	fail := func(err error) {
+		errs <- err
+		cancel()
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				mu.Lock()
+				done := total == groups*messagesPerGroup
+				mu.Unlock()
+				if done {
+					return
+				}
+
+				status, response := doJSON(t, r, http.MethodGet, "/sqs/queues/eventos.fifo/messages?max_messages=3&wait_time=0", nil)
+				if status != http.StatusOK {
+					fail(fmt.Errorf("recebimento: status %d, resposta %v", status, response))
+					return
+				}
+				for _, item := range response["messages"].([]any) {
+					message := item.(map[string]any)
+					if err := process(message); err != nil {
+						fail(err)
+						return
+					}
+					ack := map[string]any{"receipt_handle": message["receipt_handle"]}
+					if status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/ack", ack); status != http.StatusOK {
+						fail(fmt.Errorf("confirmação: status %d, resposta %v", status, response))
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if t.Failed() {
+		return
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("consumidores não terminaram: %d de %d mensagens processadas", total, groups*messagesPerGroup)
+	}
+
+	for g := 0; g < groups; g++ {
+		group := fmt.Sprintf("grupo-%d", g)
+		order := processed[group]
+		if len(order) != messagesPerGroup {
+			t.Errorf("%s: %d mensagens processadas, esperado %d", group, len(order), messagesPerGroup)
+			continue
+		}
+		for i, n := range order {
+			if n != i {
+				t.Errorf("%s processado fora de ordem: %v", group, order)
+				break
+			}
+		}
+	}
+	if parallel < 2 {
+		t.Errorf("grupos nunca foram processados em paralelo")
+	}
+	if pending := queue.pending(); pending != 0 {
+		t.Errorf("%d mensagens restantes na fila", pending)
+	}
+}
+
+// TestFIFONackKeepsGroupOrder devolve a primeira mensagem de um grupo e confere que ela é entregue de novo
+// antes das seguintes
+func TestFIFONackKeepsGroupOrder(t *testing.T) {
+	queue := newFakeFIFOQueue("eventos.fifo")
+	queue.contentDeduplication = true
+	r := newFIFOTestRouter(queue)
+
+	messages := []map[string]any{
+		{"message": "a1", "message_group_id": "a"},
+		{"message": "a2", "message_group_id": "a"},
+		{"message": "b1", "message_group_id": "b"},
+	}
+	doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/batch", map[string]any{"messages": messages})
+
+	_, first := doJSON(t, r, http.MethodGet, "/sqs/queues/eventos.fifo/messages?max_messages=1&wait_time=0", nil)
+	received := first["messages"].([]any)[0].(map[string]any)
+	if received["message"] != "a1" {
+		t.Fatalf("primeira mensagem %v, esperado a1", received["message"])
+	}
+
+	// Com a1 em processamento, o grupo a fica bloqueado e apenas b1 é entregue
+	_, blocked := doJSON(t, r, http.MethodGet, "/sqs/queues/eventos.fifo/messages?max_messages=10&wait_time=0", nil)
+	if got := blocked["messages"].([]any); len(got) != 1 || got[0].(map[string]any)["message"] != "b1" {
+		t.Fatalf("com o grupo a bloqueado foram recebidas %v, esperado apenas b1", got)
+	}
+
+	doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/nack", map[string]any{"receipt_handle": received["receipt_handle"]})
+	_, again := doJSON(t, r, http.MethodGet, "/sqs/queues/eventos.fifo/messages?max_messages=10&wait_time=0", nil)
+	got := again["messages"].([]any)
+	if len(got) != 2 || got[0].(map[string]any)["message"] != "a1" || got[1].(map[string]any)["message"] != "a2" {
+		t.Errorf("após o nack foram recebidas %v, esperado a1 e a2 em ordem", got)
+	}
+}
+
+// TestFIFOBatchSkipsGroupAfterFailure confere que, quando uma mensagem falha, as seguintes do mesmo grupo
+// não são enviadas, enquanto os demais grupos seguem normalmente
+func TestFIFOBatchSkipsGroupAfterFailure(t *testing.T) {
+	queue := newFakeFIFOQueue("eventos.fifo")
+	queue.contentDeduplication = true
+	queue.rejectBodies["a-falha"] = true
+	r := newFIFOTestRouter(queue)
+
+	// A mensagem rejeitada é a décima da requisição; a seguinte do grupo a não pode ser enviada
+	messages := make([]map[string]any, 0)
+	for i := 0; i < 9; i++ {
+		messages = append(messages, map[string]any{"message": fmt.Sprintf("b%d", i), "message_group_id": "b"})
+	}
+	messages = append(messages,
+		map[string]any{"message": "a-falha", "message_group_id": "a"},
+		map[string]any{"message": "a-depois", "message_group_id": "a"},
+		map[string]any{"message": "b-depois", "message_group_id": "b"},
+	)
+
+	status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/batch", map[string]any{"messages": messages})
+	if status != http.StatusMultiStatus {
+		t.Fatalf("status %d, esperado 207: %v", status, response)
+	}
+	results := response["results"].([]any)
+	for i, item := range results {
+		result := item.(map[string]any)
+		wantSuccess := i != 9 && i != 10
+		if result["success"] != wantSuccess {
+			t.Errorf("mensagem %d (%s): success = %v, esperado %v", i, messages[i]["message"], result["success"], wantSuccess)
+		}
+	}
+	if failed := response["failed"]; failed != float64(2) {
+		t.Errorf("failed = %v, esperado 2", failed)
+	}
+	if pending := queue.pending(); pending != 10 {
+		t.Errorf("fila com %d mensagens, esperado 10", pending)
+	}
+}
+
+func TestFIFOBatchSkipsGroupAfterFailureInSameChunk(t *testing.T) {
+	queue := newFakeFIFOQueue("eventos.fifo")
+	queue.contentDeduplication = true
+	queue.rejectBodies["a2"] = true
+	r := newFIFOTestRouter(queue)
+
+	// Todas cabem em um único lote de 10; a rejeitada fica no meio do grupo a
+	bodies := []string{"a1", "b1", "a2", "b2", "a3", "c1", "a4", "b3"}
+	messages := make([]map[string]any, 0, len(bodies))
+	for _, body := range bodies {
+		messages = append(messages, map[string]any{"message": body, "message_group_id": body[:1]})
+	}
+
+	status, response := doJSON(t, r, http.MethodPost, "/sqs/queues/eventos.fifo/messages/batch", map[string]any{"messages": messages})
+	if status != http.StatusMultiStatus {
+		t.Fatalf("status %d, esperado 207: %v", status, response)
+	}
+	failed := map[string]bool{"a2": true, "a3": true, "a4": true}
+	for i, item := range response["results"].([]any) {
+		result := item.(map[string]any)
+		if result["success"] == failed[bodies[i]] {
+			t.Errorf("mensagem %s: success = %v, erro %v", bodies[i], result["success"], result["error"])
+		}
+	}
+
+	queued := make([]string, 0)
+	queue.mu.Lock()
+	for _, message := range queue.messages {
+		queued = append(queued, message.body)
+	}
+	queue.mu.Unlock()
+	for _, body := range queued {
+		if failed[body] {
+			t.Errorf("mensagem %s chegou à fila depois da falha de a2: %v", body, queued)
+		}
+	}
+	if len(queued) != 5 {
+		t.Errorf("fila com %v, esperado a1, b1, b2, c1 e b3", queued)
+	}
+}
+
+func TestFIFOBatchChunks(t *testing.T) {
+	groups := []string{"a", "a", "a", "b", "b", "c", "a", "b"}
+	messages := make([]SendMessageRequest, 0, len(groups))
+	for _, group := range groups {
+		messages = append(messages, SendMessageRequest{Message: "x", MessageGroupID: group})
+	}
+	messages = append(messages, SendMessageRequest{Message: strings.Repeat("x", maxSQSPayloadSize+1), MessageGroupID: "a"})
+
+	chunks, oversized := fifoBatchChunks(messages)
+	want := [][]int{{0, 3, 5}, {1, 4}, {2, 7}, {6}}
+	if !reflect.DeepEqual(chunks, want) || !reflect.DeepEqual(oversized, []int{8}) {
+		t.Errorf("lotes = %v, acima do limite = %v; esperado %v e [8]", chunks, oversized, want)
+	}
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateQueueRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Atributos da fila no formato do SQS (ex.: VisibilityTimeout, MessageRetentionPeriod)
+	Attributes map[string]string `json:"attributes"`
+	// Cria uma fila FIFO; o sufixo .fifo é adicionado ao nome quando ausente
+	FIFO bool `json:"fifo"`
+	// Usa o SHA-256 do corpo como MessageDeduplicationId quando a mensagem não informa um
+	ContentBasedDeduplication bool `json:"content_based_deduplication"`
+}
+
+// applyFIFO ajusta o nome e os atributos da fila FIFO
+func (r *CreateQueueRequest) applyFIFO() error {
+	if r.FIFO && !isFIFOQueue(r.Name) {
+		r.Name += ".fifo"
+	}
+	if !isFIFOQueue(r.Name) {
+		if r.ContentBasedDeduplication {
+			return fmt.Errorf("content_based_deduplication só é aceito em filas FIFO")
+		}
+		return nil
+	}
+
+	if r.Attributes == nil {
+		r.Attributes = map[string]string{}
+	}
+	r.Attributes[string(types.QueueAttributeNameFifoQueue)] = "true"
+	if r.ContentBasedDeduplication {
+		r.Attributes[string(types.QueueAttributeNameContentBasedDeduplication)] = "true"
+	}
+	return nil
+}
+
+func (s *SQSController) CreateQueue(c *gin.Context) {
+	var req CreateQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome da fila é obrigatório"})
+		return
+	}
+	if err := req.applyFIFO(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	output, err := s.client.CreateQueue(context.TODO(), &sqs.CreateQueueInput{
+		QueueName:  aws.String(req.Name),
+		Attributes: req.Attributes,
+	})
+	if err != nil {
+		c.JSON(sqsErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao criar fila: %v", err)})
+		return
+	}
+	s.cacheQueueURL(req.Name, *output.QueueUrl)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Fila criada com sucesso",
+		"queue":      req.Name,
+		"queue_url":  *output.QueueUrl,
+		"fifo":       isFIFOQueue(req.Name),
+		"attributes": req.Attributes,
+	})
+}
+
+func (s *SQSController) ListQueues(c *gin.Context) {
+	input := &sqs.ListQueuesInput{}
+	if prefix := c.Query("prefix"); prefix != "" {
+		input.QueueNamePrefix = aws.String(prefix)
+	}
+
+	queues := make([]gin.H, 0)
+	paginator := sqs.NewListQueuesPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			c.JSON(sqsErrorStatus(err), gin.H{"error": fmt.Sprintf("Erro ao listar filas: %v", err)})
+			return
+		}
+		for _, queueURL := range page.QueueUrls {
+			// O nome da fila é o último segmento da URL
+			name := path.Base(queueURL)
+			s.cacheQueueURL(name, queueURL)
+			queues = append(queues, gin.H{
+				"name":      name,
+				"queue_url": queueURL,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queues": queues,
+	})
+}
+
+func (s *SQSController) GetQueueAttributes(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	output, err := s.client.GetQueueAttributes(context.TODO(), &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao consultar fila", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queue":      name,
+		"queue_url":  queueURL,
+		"attributes": output.Attributes,
+	})
+}
+
+func (s *SQSController) DeleteQueue(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	_, err := s.client.DeleteQueue(context.TODO(), &sqs.DeleteQueueInput{
+		QueueUrl: aws.String(queueURL),
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao deletar fila", err)
+		return
+	}
+	s.forgetQueueURL(name)
+	s.stopMonitor(name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Fila %s deletada com sucesso", name),
+	})
+}
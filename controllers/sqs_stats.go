@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Amostras guardadas por monitor; com o intervalo padrão cobrem uma hora
+const maxMonitorSamples = 720
+
+// Limites do intervalo de coleta do monitor, em segundos
+const (
+	defaultMonitorInterval = 5
+	maxMonitorInterval     = 300
+)
+
+// Tempo que as mensagens amostradas para estimar a idade ficam ocultas antes de serem liberadas
+const ageSampleVisibilityTimeout = 10
+
+// Cada amostragem soma um recebimento às mensagens lidas; com uma DLQ que as move antes disso, a amostragem é recusada
+const minSampleMaxReceiveCount = 10
+
+// QueueStats reúne os contadores aproximados da fila e, se houver DLQ, a quantidade de mensagens nela
+type QueueStats struct {
+	Visible    int64  `json:"visible"`
+	NotVisible int64  `json:"not_visible"`
+	Delayed    int64  `json:"delayed"`
+	DLQ        string `json:"dlq,omitempty"`
+	DLQDepth   *int64 `json:"dlq_depth,omitempty"`
+}
+
+func int64Attribute(attributes map[string]string, name types.QueueAttributeName) int64 {
+	value, _ := strconv.ParseInt(attributes[string(name)], 10, 64)
+	return value
+}
+
+// queueStats consulta os contadores da fila e a profundidade da DLQ, retornando também os atributos lidos
+func (s *SQSController) queueStats(ctx context.Context, queueURL string) (QueueStats, map[string]string, error) {
+	var stats QueueStats
+	output, err := s.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	if err != nil {
+		return stats, nil, err
+	}
+
+	stats.Visible = int64Attribute(output.Attributes, types.QueueAttributeNameApproximateNumberOfMessages)
+	stats.NotVisible = int64Attribute(output.Attributes, types.QueueAttributeNameApproximateNumberOfMessagesNotVisible)
+	stats.Delayed = int64Attribute(output.Attributes, types.QueueAttributeNameApproximateNumberOfMessagesDelayed)
+
+	policy, err := parseRedrivePolicy(output.Attributes)
+	if errors.Is(err, errNoDeadLetterQueue) {
+		return stats, output.Attributes, nil
+	}
+	if err != nil {
+		return stats, output.Attributes, err
+	}
+
+	stats.DLQ = queueNameFromARN(policy.DeadLetterTargetArn)
+	dlqURL, err := s.queueURL(ctx, stats.DLQ)
+	if err != nil {
+		return stats, output.Attributes, fmt.Errorf("erro ao localizar DLQ %s: %v", stats.DLQ, err)
+	}
+	dlq, err := s.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(dlqURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+	})
+	if err != nil {
+		return stats, output.Attributes, fmt.Errorf("erro ao consultar DLQ %s: %v", stats.DLQ, err)
+	}
+	depth := int64Attribute(dlq.Attributes, types.QueueAttributeNameApproximateNumberOfMessages)
+	stats.DLQDepth = &depth
+	return stats, output.Attributes, nil
+}
+
+// oldestMessageAge estima a idade da mensagem mais antiga a partir de uma amostra de até 10 mensagens,
+// liberadas logo em seguida; cada amostragem conta como um recebimento
+func (s *SQSController) oldestMessageAge(ctx context.Context, queueURL string) (time.Duration, int, error) {
+	messages, err := s.receiveMessages(ctx, sqs.ReceiveMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		VisibilityTimeout: ageSampleVisibilityTimeout,
+		AttributeNames:    []types.QueueAttributeName{types.QueueAttributeNameAll},
+	}, maxSQSBatchEntries)
+	if err != nil {
+		return 0, 0, err
+	}
+	// A liberação não segue o cancelamento da requisição: as mensagens amostradas não podem ficar ocultas
+	if err := s.releaseMessages(context.WithoutCancel(ctx), queueURL, messages); err != nil {
+		return 0, 0, err
+	}
+
+	var oldest time.Duration
+	for _, message := range messages {
+		sent, err := strconv.ParseInt(message.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64)
+		if err != nil {
+			continue
+		}
+		oldest = max(oldest, time.Since(time.UnixMilli(sent)))
+	}
+	return oldest, len(messages), nil
+}
+
+// ageSampleRestriction retorna o motivo para não amostrar a fila, ou vazio quando a amostragem é segura
+func ageSampleRestriction(name string, attributes map[string]string) string {
+	if isFIFOQueue(name) {
+		return "amostragem desativada em filas FIFO: as mensagens amostradas bloqueariam a entrega dos seus grupos"
+	}
+	policy, err := parseRedrivePolicy(attributes)
+	if err != nil {
+		return ""
+	}
+	if count, err := policy.MaxReceiveCount.Int64(); err == nil && count < minSampleMaxReceiveCount {
+		return fmt.Sprintf("amostragem desativada: a DLQ recebe as mensagens após %d recebimentos e cada amostragem conta como um", count)
+	}
+	return ""
+}
+
+// GetQueueStats retorna a quantidade de mensagens visíveis, em processamento e atrasadas, e a profundidade da DLQ.
+// Com sample_age=true estima também a idade da mensagem mais antiga, o que não é uma leitura pura: até 10 mensagens
+// são recebidas e liberadas, somando um recebimento a cada uma. Por isso a amostragem é recusada em filas FIFO e
+// em filas cuja DLQ tem maxReceiveCount abaixo de minSampleMaxReceiveCount
+func (s *SQSController) GetQueueStats(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	// Cliente desconectado cancela as consultas em andamento
+	ctx := c.Request.Context()
+	stats, attributes, err := s.queueStats(ctx, queueURL)
+	if ctx.Err() != nil {
+		c.Abort()
+		return
+	}
+	if err != nil {
+		s.sqsError(c, name, "Erro ao consultar estatísticas da fila", err)
+		return
+	}
+
+	response := gin.H{
+		"queue":       name,
+		"visible":     stats.Visible,
+		"not_visible": stats.NotVisible,
+		"delayed":     stats.Delayed,
+		"total":       stats.Visible + stats.NotVisible + stats.Delayed,
+	}
+	if stats.DLQDepth != nil {
+		response["dlq"] = stats.DLQ
+		response["dlq_depth"] = *stats.DLQDepth
+	}
+	if created := int64Attribute(attributes, types.QueueAttributeNameCreatedTimestamp); created > 0 {
+		response["created_at"] = time.Unix(created, 0).UTC()
+	}
+
+	if c.Query("sample_age") == "true" {
+		if reason := ageSampleRestriction(name, attributes); reason != "" {
+			response["age_sample"] = gin.H{"skipped": reason}
+		} else {
+			age, sampled, err := s.oldestMessageAge(ctx, queueURL)
+			if err != nil {
+				s.sqsError(c, name, "Erro ao amostrar mensagens da fila", err)
+				return
+			}
+			sample := gin.H{
+				"sampled_messages": sampled,
+				"note":             fmt.Sprintf("idade da mais antiga entre até %d mensagens amostradas, não necessariamente a mais antiga da fila", maxSQSBatchEntries),
+			}
+			if sampled > 0 {
+				sample["oldest_age_seconds"] = int64(age.Seconds())
+			}
+			response["age_sample"] = sample
+		}
+	}
+
+	// O histórico do monitor permite estimar há quanto tempo a fila não fica vazia
+	if since, found := s.backlogSince(name); found {
+		response["backlog_age_seconds"] = int64(time.Since(since).Seconds())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type QueueSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	QueueStats
+	Error string `json:"error,omitempty"`
+}
+
+// queueMonitor coleta as estatísticas de uma fila em intervalos fixos e guarda as últimas amostras
+type queueMonitor struct {
+	interval  time.Duration
+	startedAt time.Time
+	cancel    context.CancelFunc
+	samples   []QueueSample
+}
+
+type StartMonitorRequest struct {
+	// Intervalo entre coletas, em segundos
+	IntervalSeconds int `json:"interval_seconds"`
+}
+
+// StartQueueMonitor inicia (ou reinicia com outro intervalo) a coleta periódica das estatísticas da fila
+func (s *SQSController) StartQueueMonitor(c *gin.Context) {
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+
+	var req StartMonitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Requisição inválida: %v", err)})
+		return
+	}
+	if req.IntervalSeconds == 0 {
+		req.IntervalSeconds = defaultMonitorInterval
+	}
+	if req.IntervalSeconds < 1 || req.IntervalSeconds > maxMonitorInterval {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("interval_seconds deve estar entre 1 e %d", maxMonitorInterval)})
+		return
+	}
+
+	// O monitor vive até ser parado ou substituído, ou até o encerramento do servidor
+	ctx, cancel := context.WithCancel(s.lifetime)
+	monitor := &queueMonitor{
+		interval:  time.Duration(req.IntervalSeconds) * time.Second,
+		startedAt: time.Now(),
+		cancel:    cancel,
+		samples:   make([]QueueSample, 0),
+	}
+
+	s.monitorsMu.Lock()
+	if previous, found := s.monitors[name]; found {
+		previous.cancel()
+	}
+	s.monitors[name] = monitor
+	s.monitorsMu.Unlock()
+
+	go s.runMonitor(ctx, monitor, queueURL)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Monitor iniciado com sucesso",
+		"queue":            name,
+		"interval_seconds": req.IntervalSeconds,
+		"max_samples":      maxMonitorSamples,
+	})
+}
+
+func (s *SQSController) runMonitor(ctx context.Context, monitor *queueMonitor, queueURL string) {
+	ticker := time.NewTicker(monitor.interval)
+	defer ticker.Stop()
+
+	for {
+		stats, _, err := s.queueStats(ctx, queueURL)
+		if ctx.Err() != nil {
+			return
+		}
+		sample := QueueSample{Timestamp: time.Now(), QueueStats: stats}
+		if err != nil {
+			sample.Error = err.Error()
+		}
+
+		s.monitorsMu.Lock()
+		monitor.samples = append(monitor.samples, sample)
+		if overflow := len(monitor.samples) - maxMonitorSamples; overflow > 0 {
+			monitor.samples = append([]QueueSample(nil), monitor.samples[overflow:]...)
+		}
+		s.monitorsMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetQueueMonitor retorna o histórico coletado e a variação de mensagens visíveis por minuto no período
+func (s *SQSController) GetQueueMonitor(c *gin.Context) {
+	name := c.Param("name")
+
+	s.monitorsMu.Lock()
+	monitor, found := s.monitors[name]
+	var samples []QueueSample
+	if found {
+		samples = make([]QueueSample, len(monitor.samples))
+		copy(samples, monitor.samples)
+	}
+	s.monitorsMu.Unlock()
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Nenhum monitor ativo para a fila %s", name)})
+		return
+	}
+
+	response := gin.H{
+		"queue":            name,
+		"interval_seconds": int(monitor.interval.Seconds()),
+		"started_at":       monitor.startedAt,
+		"samples":          samples,
+		"count":            len(samples),
+	}
+	if len(samples) > 1 {
+		first, last := samples[0], samples[len(samples)-1]
+		if minutes := last.Timestamp.Sub(first.Timestamp).Minutes(); minutes > 0 {
+			response["backlog_growth_per_minute"] = float64(last.Visible-first.Visible) / minutes
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// StopQueueMonitor interrompe a coleta e descarta o histórico da fila
+func (s *SQSController) StopQueueMonitor(c *gin.Context) {
+	name := c.Param("name")
+	if !s.stopMonitor(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Nenhum monitor ativo para a fila %s", name)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Monitor encerrado com sucesso",
+		"queue":   name,
+	})
+}
+
+func (s *SQSController) stopMonitor(name string) bool {
+	s.monitorsMu.Lock()
+	defer s.monitorsMu.Unlock()
+
+	monitor, found := s.monitors[name]
+	if found {
+		monitor.cancel()
+		delete(s.monitors, name)
+	}
+	return found
+}
+
+// backlogSince retorna, segundo o monitor da fila, desde quando ela tem mensagens visíveis sem ter esvaziado
+func (s *SQSController) backlogSince(name string) (time.Time, bool) {
+	s.monitorsMu.Lock()
+	defer s.monitorsMu.Unlock()
+
+	monitor, found := s.monitors[name]
+	if !found {
+		return time.Time{}, false
+	}
+
+	var since time.Time
+	for i := len(monitor.samples) - 1; i >= 0; i-- {
+		sample := monitor.samples[i]
+		if sample.Error != "" {
+			continue
+		}
+		if sample.Visible == 0 {
+			break
+		}
+		since = sample.Timestamp
+	}
+	return since, !since.IsZero()
+}
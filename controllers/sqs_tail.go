@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// Modos do tail: peek devolve as mensagens à fila logo após lê-las; consume as remove depois de entregues.
+// Cada leitura do peek soma um recebimento às mensagens, então ele é recusado em filas com DLQ
+const (
+	TailModePeek    = "peek"
+	TailModeConsume = "consume"
+)
+
+// Mensagens aguardando envio ao cliente. No modo peek o excedente é descartado para o tail continuar
+// acompanhando as mensagens novas; no modo consume a fila só é lida quando há espaço
+const (
+	tailPeekBuffer    = 100
+	tailConsumeBuffer = maxSQSBatchEntries
+)
+
+const (
+	// IDs já enviados no modo peek, para não repetir mensagens devolvidas à fila
+	tailSeenLimit = 10000
+	// Tempo que as mensagens do modo consume ficam ocultas até serem entregues e removidas
+	tailVisibilityTimeout = 60
+	// Espera entre leituras que só retornaram mensagens já enviadas, dobrada até o máximo
+	tailIdleDelay    = time.Second
+	tailMaxIdleDelay = 30 * time.Second
+	// Espera antes de ler novamente após um erro do SQS
+	tailErrorDelay = 5 * time.Second
+
+	tailHeartbeatInterval = 15 * time.Second
+	tailWriteTimeout      = 10 * time.Second
+)
+
+type tailOptions struct {
+	mode     string
+	waitTime int
+}
+
+func parseTailOptions(c *gin.Context) (tailOptions, error) {
+	opts := tailOptions{mode: c.DefaultQuery("mode", TailModePeek)}
+	if opts.mode != TailModePeek && opts.mode != TailModeConsume {
+		return opts, fmt.Errorf("mode deve ser %s ou %s", TailModePeek, TailModeConsume)
+	}
+	waitTime, err := boundedQueryParam(c, "wait_time", 1, maxWaitTimeSeconds, maxWaitTimeSeconds)
+	opts.waitTime = waitTime
+	return opts, err
+}
+
+// queueTail lê a fila em segundo plano e entrega as mensagens por um canal limitado
+type queueTail struct {
+	s        *SQSController
+	queueURL string
+	opts     tailOptions
+
+	messages chan types.Message
+	errors   chan error
+	dropped  atomic.Int64
+
+	seen      map[string]bool
+	seenOrder []string
+}
+
+// checkPeekAllowed recusa o modo peek em filas com DLQ: as releituras de mensagens ainda não consumidas
+// contam como recebimentos e as moveriam para a DLQ após maxReceiveCount leituras
+func (s *SQSController) checkPeekAllowed(c *gin.Context, name, queueURL string) bool {
+	output, err := s.client.GetQueueAttributes(c.Request.Context(), &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+	})
+	if err != nil {
+		s.sqsError(c, name, "Erro ao consultar atributos da fila", err)
+		return false
+	}
+	policy, err := parseRedrivePolicy(output.Attributes)
+	if errors.Is(err, errNoDeadLetterQueue) {
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf(
+		"O modo peek não é permitido em filas com DLQ: cada leitura conta como recebimento e as mensagens seriam movidas para %s após %s leituras",
+		queueNameFromARN(policy.DeadLetterTargetArn), policy.MaxReceiveCount)})
+	return false
+}
+
+// tailContext deriva o contexto do tail da requisição e do contexto de vida do servidor, para que os
+// tails abertos terminem no desligamento em vez de segurar o srv.Shutdown até o prazo
+func (s *SQSController) tailContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(s.lifetime, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// startTail inicia a leitura da fila até o contexto ser cancelado
+func (s *SQSController) startTail(ctx context.Context, queueURL string, opts tailOptions) *queueTail {
+	buffer := tailPeekBuffer
+	if opts.mode == TailModeConsume {
+		buffer = tailConsumeBuffer
+	}
+	tail := &queueTail{
+		s:        s,
+		queueURL: queueURL,
+		opts:     opts,
+		messages: make(chan types.Message, buffer),
+		errors:   make(chan error, 1),
+		seen:     make(map[string]bool),
+	}
+	go tail.poll(ctx)
+	return tail
+}
+
+// sleepContext espera o intervalo ou até o contexto ser cancelado
+func sleepContext(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (t *queueTail) reportError(err error) {
+	select {
+	case t.errors <- err:
+	default:
+	}
+}
+
+// markSeen registra o ID da mensagem e retorna false se ela já tinha sido enviada
+func (t *queueTail) markSeen(id string) bool {
+	if t.seen[id] {
+		return false
+	}
+	t.seen[id] = true
+	t.seenOrder = append(t.seenOrder, id)
+	if len(t.seenOrder) > tailSeenLimit {
+		delete(t.seen, t.seenOrder[0])
+		t.seenOrder = t.seenOrder[1:]
+	}
+	return true
+}
+
+func (t *queueTail) poll(ctx context.Context) {
+	defer close(t.messages)
+
+	idleDelay := tailIdleDelay
+	for ctx.Err() == nil {
+		maxMessages := maxSQSBatchEntries
+		if t.opts.mode == TailModeConsume {
+			// Só o poller envia ao canal, então o espaço livre não diminui até a leitura terminar
+			maxMessages = cap(t.messages) - len(t.messages)
+			if maxMessages == 0 {
+				sleepContext(ctx, tailIdleDelay)
+				continue
+			}
+		}
+
+		output, err := t.s.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(t.queueURL),
+			MaxNumberOfMessages:   int32(maxMessages),
+			WaitTimeSeconds:       int32(t.opts.waitTime),
+			VisibilityTimeout:     tailVisibilityTimeout,
+			AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				t.reportError(fmt.Errorf("erro ao receber mensagens: %v", err))
+				sleepContext(ctx, tailErrorDelay)
+			}
+			continue
+		}
+
+		if t.opts.mode == TailModeConsume {
+			for _, message := range output.Messages {
+				t.messages <- message
+			}
+			continue
+		}
+
+		if err := t.s.releaseMessages(context.WithoutCancel(ctx), t.queueURL, output.Messages); err != nil {
+			t.reportError(fmt.Errorf("erro ao devolver mensagens à fila: %v", err))
+		}
+		fresh := 0
+		for _, message := range output.Messages {
+			if !t.markSeen(aws.ToString(message.MessageId)) {
+				continue
+			}
+			fresh++
+			select {
+			case t.messages <- message:
+			default:
+				t.dropped.Add(1)
+			}
+		}
+
+		// Mensagens já enviadas continuam visíveis; cada nova leitura conta como um recebimento,
+		// então o intervalo cresce enquanto não surgirem mensagens novas
+		if fresh == 0 && len(output.Messages) > 0 {
+			sleepContext(ctx, idleDelay)
+			idleDelay = min(idleDelay*2, tailMaxIdleDelay)
+		} else if fresh > 0 {
+			idleDelay = tailIdleDelay
+		}
+	}
+}
+
+// delivered remove a mensagem no modo consume, depois de enviada ao cliente
+func (t *queueTail) delivered(ctx context.Context, message types.Message) {
+	if t.opts.mode != TailModeConsume {
+		return
+	}
+	_, err := t.s.client.DeleteMessage(context.WithoutCancel(ctx), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(t.queueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	if err != nil {
+		t.reportError(fmt.Errorf("erro ao remover mensagem %s: %v", aws.ToString(message.MessageId), err))
+	}
+}
+
+// close aguarda o fim da leitura e, no modo consume, devolve à fila as mensagens não entregues
+func (t *queueTail) close(undelivered ...types.Message) {
+	for message := range t.messages {
+		undelivered = append(undelivered, message)
+	}
+	if t.opts.mode == TailModeConsume && len(undelivered) > 0 {
+		// O cliente já desconectou; a falha fica no log porque as mensagens só voltam após o visibility timeout
+		if err := t.s.releaseMessages(context.Background(), t.queueURL, undelivered); err != nil {
+			log.Printf("Tail de %s: erro ao devolver mensagens não entregues: %v", t.queueURL, err)
+		}
+	}
+}
+
+// pump envia os eventos do tail ao cliente até o contexto ser cancelado ou o envio falhar
+func (t *queueTail) pump(ctx context.Context, send func(event string, data any) error) {
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var reported int64
+	for {
+		select {
+		case <-ctx.Done():
+			t.close()
+			return
+		case err := <-t.errors:
+			if send("error", gin.H{"error": err.Error()}) != nil {
+				t.close()
+				return
+			}
+		case <-heartbeat.C:
+			if send("ping", gin.H{"time": time.Now()}) != nil {
+				t.close()
+				return
+			}
+		case message, ok := <-t.messages:
+			if !ok {
+				return
+			}
+			// Mensagens descartadas porque o cliente não acompanhou o ritmo da fila
+			if dropped := t.dropped.Load(); dropped > reported {
+				reported = dropped
+				if send("dropped", gin.H{"dropped": dropped}) != nil {
+					t.close(message)
+					return
+				}
+			}
+
+			response := messageResponse(message)
+			// O receipt handle não é útil ao cliente: a mensagem já foi devolvida à fila ou será removida
+			delete(response, "receipt_handle")
+			response["mode"] = t.opts.mode
+			if send("message", response) != nil {
+				t.close(message)
+				return
+			}
+			t.delivered(ctx, message)
+		}
+	}
+}
+
+// TailSSE envia as mensagens da fila em tempo real como Server-Sent Events
+func (s *SQSController) TailSSE(c *gin.Context) {
+	opts, err := parseTailOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+	if opts.mode == TailModePeek && !s.checkPeekAllowed(c, name, queueURL) {
+		return
+	}
+
+	ctx, cancel := s.tailContext(c.Request.Context())
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Desativa o buffer de proxies como o nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Com o prazo de escrita, um cliente parado não segura as mensagens do modo consume até o visibility timeout
+	writer := http.NewResponseController(c.Writer)
+	send := func(event string, data any) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if err := writer.SetWriteDeadline(time.Now().Add(tailWriteTimeout)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+		return writer.Flush()
+	}
+	if send("open", gin.H{"queue": name, "mode": opts.mode}) != nil {
+		return
+	}
+
+	tail := s.startTail(ctx, queueURL, opts)
+	tail.pump(ctx, func(event string, data any) error {
+		err := send(event, data)
+		if err != nil {
+			cancel()
+		}
+		return err
+	})
+}
+
+// TailWebSocket envia as mensagens da fila em tempo real por WebSocket, uma por frame JSON
+func (s *SQSController) TailWebSocket(c *gin.Context) {
+	opts, err := parseTailOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name, queueURL, ok := s.resolveQueue(c)
+	if !ok {
+		return
+	}
+	if opts.mode == TailModePeek && !s.checkPeekAllowed(c, name, queueURL) {
+		return
+	}
+
+	// Sem Handshake o Origin não é verificado, assim como nas demais rotas da API
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		// A conexão sequestrada não segue o contexto da requisição, só o de vida do servidor
+		ctx, cancel := s.tailContext(context.Background())
+		defer cancel()
+
+		// O cliente não envia comandos; a leitura só detecta a desconexão
+		go func() {
+			defer cancel()
+			var discard string
+			for websocket.Message.Receive(ws, &discard) == nil {
+			}
+		}()
+
+		send := func(event string, data any) error {
+			ws.SetWriteDeadline(time.Now().Add(tailWriteTimeout))
+			err := websocket.JSON.Send(ws, gin.H{"type": event, "data": data})
+			if err != nil {
+				cancel()
+			}
+			return err
+		}
+		if send("open", gin.H{"queue": name, "mode": opts.mode}) != nil {
+			return
+		}
+
+		tail := s.startTail(ctx, queueURL, opts)
+		tail.pump(ctx, send)
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
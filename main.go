@@ -1,15 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"localstackdemo/config"
 	"localstackdemo/routes"
+	"localstackdemo/s3sync"
+	"localstackdemo/sqsconsumer"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/gin-gonic/gin"
 )
 
+// Prazo para concluir requisições e mensagens em processamento no encerramento
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// Configurar AWS
 	cfg, err := config.GetAWSConfig()
@@ -17,13 +34,158 @@ func main() {
 		log.Fatalf("Erro ao carregar configuração AWS: %v", err)
 	}
 
+	// Subcomando de sincronização de diretórios
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		if err := runSync(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Erro na sincronização: %v", err)
+		}
+		return
+	}
+
 	// Configurar Gin
 	r := gin.Default()
 
+	// Consumidores SQS em segundo plano
+	consumers := setupConsumers(cfg, config.GetSQSConfig())
+
+	// Contexto cancelado no encerramento, que interrompe as tarefas em segundo plano e os tails dos controllers
+	lifetime, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
 	// Configurar rotas
-	routes.SetupRoutes(r, cfg)
+	waitControllers := routes.SetupRoutes(lifetime, r, cfg, consumers)
+
+	consumers.Start()
 
 	// Iniciar servidor
+	srv := &http.Server{Addr: ":6000", Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Erro ao iniciar servidor: %v", err)
+		}
+	}()
 	fmt.Println("Servidor rodando na porta 6000")
-	r.Run(":6000")
+
+	// Encerrar com SIGINT ou SIGTERM, aguardando requisições e mensagens em processamento
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	fmt.Println("Encerrando servidor...")
+	stopBackground()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// Os consumidores e as tarefas dos controllers drenam em paralelo ao encerramento das requisições HTTP
+	consumersDone := make(chan error, 1)
+	go func() {
+		consumersDone <- consumers.Shutdown(shutdownCtx)
+	}()
+	controllersDone := make(chan error, 1)
+	go func() {
+		controllersDone <- waitControllers(shutdownCtx)
+	}()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Erro ao encerrar servidor: %v", err)
+	}
+	if err := <-consumersDone; err != nil {
+		log.Printf("Erro ao encerrar consumidores: %v", err)
+	}
+	if err := <-controllersDone; err != nil {
+		log.Printf("Erro ao encerrar tarefas em segundo plano: %v", err)
+	}
+}
+
+// setupConsumers registra um consumidor para cada fila de SQS_CONSUMER_QUEUES
+func setupConsumers(cfg aws.Config, sqsCfg config.SQSConfig) *sqsconsumer.Manager {
+	consumers := sqsconsumer.NewManager(sqs.NewFromConfig(cfg))
+	for _, queue := range sqsCfg.ConsumerQueues {
+		err := consumers.Register(queue, logMessage, sqsconsumer.Options{
+			Concurrency: sqsCfg.ConsumerConcurrency,
+			MaxAttempts: sqsCfg.ConsumerMaxAttempts,
+			PoisonQueue: sqsCfg.ConsumerPoisonQueue,
+		})
+		if err != nil {
+			log.Printf("Consumidor SQS %s ignorado: %v", queue, err)
+		}
+	}
+	return consumers
+}
+
+// logMessage é o handler padrão: registra a mensagem no log e a confirma
+func logMessage(ctx context.Context, message sqsconsumer.Message) error {
+	log.Printf("Mensagem %s recebida da fila %s (tentativa %d): %s",
+		aws.ToString(message.MessageId), message.Queue, message.Attempt, aws.ToString(message.Body))
+	return nil
+}
+
+// parseS3URL separa s3://bucket/prefixo em bucket e prefixo
+func parseS3URL(value string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(value, "s3://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	return bucket, prefix, bucket != ""
+}
+
+func runSync(cfg aws.Config, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	deleteExtra := flags.Bool("delete", false, "remove do destino os arquivos que não existem na origem")
+	dryRun := flags.Bool("dry-run", false, "apenas lista as ações, sem executá-las")
+	concurrency := flags.Int("concurrency", 4, "número de transferências simultâneas")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Uso: go run main.go sync [opções] <origem> <destino>")
+		fmt.Fprintln(flags.Output(), "  <diretório> s3://bucket/prefixo  envia o diretório para o bucket")
+		fmt.Fprintln(flags.Output(), "  s3://bucket/prefixo <diretório>  baixa o prefixo para o diretório")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("informe a origem e o destino")
+	}
+	source, target := flags.Arg(0), flags.Arg(1)
+
+	opts := s3sync.Options{
+		Delete:      *deleteExtra,
+		DryRun:      *dryRun,
+		Concurrency: *concurrency,
+	}
+	if bucket, prefix, ok := parseS3URL(target); ok {
+		opts.Direction, opts.LocalDir, opts.Bucket, opts.Prefix = s3sync.Upload, source, bucket, prefix
+	} else if bucket, prefix, ok := parseS3URL(source); ok {
+		opts.Direction, opts.LocalDir, opts.Bucket, opts.Prefix = s3sync.Download, target, bucket, prefix
+	} else {
+		return fmt.Errorf("origem ou destino deve ser uma URL s3://bucket/prefixo")
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+	result, err := s3sync.New(client).Run(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if result.DryRun {
+		prefix = "(dry-run) "
+	}
+	for _, action := range result.Actions {
+		line := fmt.Sprintf("%s%s: %s <-> s3://%s/%s", prefix, action.Type, action.Path, opts.Bucket, action.Key)
+		if action.Reason != "" {
+			line += fmt.Sprintf(" (%s)", action.Reason)
+		}
+		if action.Error != "" {
+			line += fmt.Sprintf(" ERRO: %s", action.Error)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%d ação(ões), %d sem alteração, %d falha(s)\n", len(result.Actions), result.Skipped, result.Failed)
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d transferência(s) falharam", result.Failed)
+	}
+	return nil
 }
@@ -1,27 +1,120 @@
 package routes
 
 import (
+	"context"
+
+	"localstackdemo/config"
 	"localstackdemo/controllers"
+	"localstackdemo/sqsconsumer"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(r *gin.Engine, cfg aws.Config) {
-	s3Controller := controllers.NewS3Controller(cfg)
-	sqsController := controllers.NewSQSController(cfg)
+// SetupRoutes registra as rotas da API; ctx acompanha a vida do servidor e encerra as tarefas em segundo plano dos controllers.
+// A função retornada aguarda essas tarefas no encerramento, depois que ctx foi cancelado
+func SetupRoutes(ctx context.Context, r *gin.Engine, cfg aws.Config, consumers *sqsconsumer.Manager) func(context.Context) error {
+	s3Config := config.GetS3Config()
+	s3Controller := controllers.NewS3Controller(ctx, cfg, s3Config)
+	s3NotificationController := controllers.NewS3NotificationController(ctx, cfg, s3Config)
+	sqsController := controllers.NewSQSController(ctx, cfg, config.GetSQSConfig())
+	sqsConsumerController := controllers.NewSQSConsumerController(consumers)
 
 	// Grupo de rotas S3
 	s3 := r.Group("/s3")
 	{
 		s3.POST("/upload", s3Controller.UploadFile)
+		s3.POST("/copy", s3Controller.CopyFile)
+		s3.POST("/move", s3Controller.MoveFile)
+		s3.POST("/sync", s3Controller.SyncDirectory)
+		s3.GET("/archive", s3Controller.DownloadArchive)
+
+		s3.POST("/buckets", s3Controller.CreateBucket)
+		s3.GET("/buckets", s3Controller.ListBuckets)
+		s3.HEAD("/buckets/:bucket", s3Controller.HeadBucket)
+		s3.DELETE("/buckets/:bucket", s3Controller.DeleteBucket)
+
+		s3.POST("/buckets/:bucket/objects", s3Controller.UploadFile)
+		s3.GET("/buckets/:bucket/objects", s3Controller.ListObjects)
+		s3.GET("/buckets/:bucket/objects/*key", s3Controller.DownloadFile)
+		s3.HEAD("/buckets/:bucket/objects/*key", s3Controller.HeadFile)
+		s3.DELETE("/buckets/:bucket/objects/*key", s3Controller.DeleteFile)
+		s3.GET("/buckets/:bucket/archive", s3Controller.DownloadArchive)
+
+		s3.GET("/buckets/:bucket/tags/*key", s3Controller.GetObjectTags)
+		s3.PUT("/buckets/:bucket/tags/*key", s3Controller.PutObjectTags)
+		s3.GET("/buckets/:bucket/thumbnails/*key", s3Controller.GetThumbnails)
+		s3.POST("/buckets/:bucket/select/*key", s3Controller.SelectObjectContent)
+
+		s3.GET("/buckets/:bucket/versioning", s3Controller.GetBucketVersioning)
+		s3.PUT("/buckets/:bucket/versioning", s3Controller.PutBucketVersioning)
+		s3.GET("/buckets/:bucket/versions/*key", s3Controller.ListObjectVersions)
+		s3.POST("/buckets/:bucket/restore/*key", s3Controller.RestoreObjectVersion)
+
+		s3.GET("/buckets/:bucket/encryption", s3Controller.GetBucketEncryption)
+		s3.PUT("/buckets/:bucket/encryption", s3Controller.PutBucketEncryption)
+		s3.DELETE("/buckets/:bucket/encryption", s3Controller.DeleteBucketEncryption)
+
+		s3.GET("/buckets/:bucket/lifecycle", s3Controller.GetBucketLifecycle)
+		s3.PUT("/buckets/:bucket/lifecycle", s3Controller.PutBucketLifecycle)
+		s3.DELETE("/buckets/:bucket/lifecycle", s3Controller.DeleteBucketLifecycle)
+
+		s3.GET("/buckets/:bucket/object-lock", s3Controller.GetObjectLockConfiguration)
+		s3.PUT("/buckets/:bucket/object-lock", s3Controller.PutObjectLockConfiguration)
+		s3.GET("/buckets/:bucket/retention/*key", s3Controller.GetObjectRetention)
+		s3.PUT("/buckets/:bucket/retention/*key", s3Controller.PutObjectRetention)
+		s3.GET("/buckets/:bucket/legal-hold/*key", s3Controller.GetObjectLegalHold)
+		s3.PUT("/buckets/:bucket/legal-hold/*key", s3Controller.PutObjectLegalHold)
+
+		s3.GET("/buckets/:bucket/cors", s3Controller.GetBucketCORS)
+		s3.PUT("/buckets/:bucket/cors", s3Controller.PutBucketCORS)
+		s3.DELETE("/buckets/:bucket/cors", s3Controller.DeleteBucketCORS)
+
+		s3.GET("/buckets/:bucket/notifications", s3NotificationController.GetBucketNotifications)
+		s3.PUT("/buckets/:bucket/notifications", s3NotificationController.PutBucketNotifications)
+		s3.DELETE("/buckets/:bucket/notifications", s3NotificationController.DeleteBucketNotifications)
+		s3.GET("/events", s3NotificationController.ListEvents)
+		s3.DELETE("/events", s3NotificationController.ClearEvents)
 	}
 
 	// Grupo de rotas SQS
 	sqs := r.Group("/sqs")
 	{
+		// Atalhos para a fila padrão
 		sqs.POST("/send", sqsController.SendMessage)
+		sqs.POST("/send/batch", sqsController.SendMessageBatch)
 		sqs.GET("/receive", sqsController.ReceiveMessage)
+		sqs.POST("/ack", sqsController.AckMessage)
+		sqs.POST("/ack/batch", sqsController.AckMessageBatch)
+		sqs.POST("/visibility", sqsController.ChangeVisibility)
+		sqs.POST("/nack", sqsController.NackMessage)
+		sqs.GET("/tail", sqsController.TailSSE)
+		sqs.GET("/tail/ws", sqsController.TailWebSocket)
+
+		sqs.POST("/queues", sqsController.CreateQueue)
+		sqs.GET("/queues", sqsController.ListQueues)
+		sqs.GET("/queues/:name", sqsController.GetQueueAttributes)
+		sqs.DELETE("/queues/:name", sqsController.DeleteQueue)
+		sqs.POST("/queues/:name/messages", sqsController.SendMessage)
+		sqs.GET("/queues/:name/messages", sqsController.ReceiveMessage)
+		sqs.POST("/queues/:name/messages/batch", sqsController.SendMessageBatch)
+		sqs.POST("/queues/:name/messages/ack", sqsController.AckMessage)
+		sqs.POST("/queues/:name/messages/ack/batch", sqsController.AckMessageBatch)
+		sqs.POST("/queues/:name/messages/visibility", sqsController.ChangeVisibility)
+		sqs.POST("/queues/:name/messages/nack", sqsController.NackMessage)
+		sqs.PUT("/queues/:name/dlq", sqsController.SetDeadLetterQueue)
+		sqs.GET("/queues/:name/dlq", sqsController.GetDeadLetterQueue)
+		sqs.DELETE("/queues/:name/dlq", sqsController.RemoveDeadLetterQueue)
+		sqs.GET("/queues/:name/dlq/messages", sqsController.ListDeadLetters)
+		sqs.POST("/queues/:name/dlq/redrive", sqsController.RedriveDeadLetters)
+		sqs.GET("/queues/:name/stats", sqsController.GetQueueStats)
+		sqs.POST("/queues/:name/monitor", sqsController.StartQueueMonitor)
+		sqs.GET("/queues/:name/monitor", sqsController.GetQueueMonitor)
+		sqs.DELETE("/queues/:name/monitor", sqsController.StopQueueMonitor)
+		sqs.GET("/queues/:name/tail", sqsController.TailSSE)
+		sqs.GET("/queues/:name/tail/ws", sqsController.TailWebSocket)
+
+		sqs.GET("/consumers", sqsConsumerController.ListConsumers)
 	}
 
 	// Grupo de rotas SNS
@@ -60,4 +153,6 @@ func SetupRoutes(r *gin.Engine, cfg aws.Config) {
 		dynamo.PUT("/:id", dynamoController.UpdateUser)
 		dynamo.DELETE("/:id", dynamoController.DeleteUser)
 	}
+
+	return s3Controller.Shutdown
 }
@@ -0,0 +1,403 @@
+package s3select
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Column é um campo nomeado de um registro, mantendo a ordem de origem
+type Column struct {
+	Name  string
+	Value any
+}
+
+// Row é um registro de entrada ou de saída; serializado como objeto JSON na ordem das colunas
+type Row []Column
+
+func (r Row) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(column.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(column.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// index retorna a posição da coluna com o nome exato, ou -1
+func (r Row) index(name string) int {
+	for i, column := range r {
+		if column.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup resolve o caminho no registro; o primeiro nome ignora maiúsculas e aceita posições como _1
+func (r Row) lookup(path []string) (any, bool) {
+	var value any
+	found := false
+	if i := r.index(path[0]); i >= 0 {
+		value, found = r[i].Value, true
+	}
+	if !found {
+		for _, column := range r {
+			if strings.EqualFold(column.Name, path[0]) {
+				value, found = column.Value, true
+				break
+			}
+		}
+	}
+	if !found && strings.HasPrefix(path[0], "_") {
+		if index, err := strconv.Atoi(path[0][1:]); err == nil && index >= 1 && index <= len(r) {
+			value, found = r[index-1].Value, true
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	for _, name := range path[1:] {
+		object, ok := value.(Row)
+		if !ok {
+			return nil, false
+		}
+		i := object.index(name)
+		if i < 0 {
+			return nil, false
+		}
+		value = object[i].Value
+	}
+	return value, true
+}
+
+// Match indica se o registro atende à condição WHERE; retorna erro quando os tipos dos valores
+// não admitem o operador, como em s.ativo < 'x' com um campo booleano
+func (q *Query) Match(row Row) (bool, error) {
+	if q.where == nil {
+		return true, nil
+	}
+	result, err := q.where.eval(q, row)
+	return result == truthTrue, err
+}
+
+// Project aplica a lista de campos do SELECT ao registro
+func (q *Query) Project(row Row) Row {
+	if len(q.fields) == 0 {
+		return row
+	}
+	projected := make(Row, 0, len(q.fields))
+	for _, f := range q.fields {
+		value, _ := row.lookup(q.resolve(f.path))
+		projected = append(projected, Column{Name: f.name, Value: value})
+	}
+	return projected
+}
+
+// resolve remove do caminho o alias da tabela (s.nome vira nome)
+func (q *Query) resolve(path []string) []string {
+	if len(path) > 1 && (strings.EqualFold(path[0], q.alias) || strings.EqualFold(path[0], "s3object")) {
+		return path[1:]
+	}
+	return path
+}
+
+type operand interface {
+	get(q *Query, row Row) (any, bool)
+}
+
+type literal struct {
+	value any
+}
+
+type field struct {
+	path []string
+}
+
+// castOperand converte o valor para o tipo informado, como em CAST(s.idade AS INT)
+type castOperand struct {
+	inner    operand
+	typeName string
+}
+
+func (l literal) get(*Query, Row) (any, bool) {
+	return l.value, true
+}
+
+func (f field) get(q *Query, row Row) (any, bool) {
+	return row.lookup(q.resolve(f.path))
+}
+
+// get retorna ausente quando o valor não pode ser convertido, o que torna a comparação falsa
+func (c castOperand) get(q *Query, row Row) (any, bool) {
+	value, found := c.inner.get(q, row)
+	if !found || value == nil {
+		return value, found
+	}
+	switch c.typeName {
+	case "string":
+		return toString(value), true
+	case "int", "integer":
+		n, ok := toNumber(value)
+		return math.Trunc(n), ok
+	}
+	return toNumber(value)
+}
+
+// truth é o resultado de uma condição na lógica de três valores do SQL: comparações com nulos são
+// desconhecidas, NOT de desconhecido continua desconhecido e o WHERE só aceita verdadeiro
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(value bool) truth {
+	if value {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+type condition interface {
+	eval(q *Query, row Row) (truth, error)
+}
+
+type andCondition struct{ left, right condition }
+type orCondition struct{ left, right condition }
+type notCondition struct{ inner condition }
+
+// evalBoth avalia os dois lados sem curto-circuito, para que um erro de tipo apareça em qualquer registro
+func evalBoth(q *Query, row Row, left, right condition) (truth, truth, error) {
+	leftResult, err := left.eval(q, row)
+	if err != nil {
+		return truthUnknown, truthUnknown, err
+	}
+	rightResult, err := right.eval(q, row)
+	return leftResult, rightResult, err
+}
+
+func (c andCondition) eval(q *Query, row Row) (truth, error) {
+	left, right, err := evalBoth(q, row, c.left, c.right)
+	switch {
+	case err != nil:
+		return truthUnknown, err
+	case left == truthFalse || right == truthFalse:
+		return truthFalse, nil
+	case left == truthUnknown || right == truthUnknown:
+		return truthUnknown, nil
+	}
+	return truthTrue, nil
+}
+
+func (c orCondition) eval(q *Query, row Row) (truth, error) {
+	left, right, err := evalBoth(q, row, c.left, c.right)
+	switch {
+	case err != nil:
+		return truthUnknown, err
+	case left == truthTrue || right == truthTrue:
+		return truthTrue, nil
+	case left == truthUnknown || right == truthUnknown:
+		return truthUnknown, nil
+	}
+	return truthFalse, nil
+}
+
+func (c notCondition) eval(q *Query, row Row) (truth, error) {
+	inner, err := c.inner.eval(q, row)
+	switch {
+	case err != nil:
+		return truthUnknown, err
+	case inner == truthTrue:
+		return truthFalse, nil
+	case inner == truthFalse:
+		return truthTrue, nil
+	}
+	return truthUnknown, nil
+}
+
+type nullCondition struct {
+	operand operand
+	negated bool
+}
+
+func (c nullCondition) eval(q *Query, row Row) (truth, error) {
+	value, found := c.operand.get(q, row)
+	isNull := !found || value == nil
+	return truthOf(isNull != c.negated), nil
+}
+
+type likeCondition struct {
+	operand operand
+	pattern *regexp.Regexp
+	negated bool
+}
+
+func (c likeCondition) eval(q *Query, row Row) (truth, error) {
+	value, found := c.operand.get(q, row)
+	if !found || value == nil {
+		return truthUnknown, nil
+	}
+	return truthOf(c.pattern.MatchString(toString(value)) != c.negated), nil
+}
+
+// likePattern converte os curingas % e _ do LIKE em uma expressão regular
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	for _, ch := range pattern {
+		switch ch {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+type comparison struct {
+	left  operand
+	op    string
+	right operand
+}
+
+func (c comparison) eval(q *Query, row Row) (truth, error) {
+	left, foundLeft := c.left.get(q, row)
+	right, foundRight := c.right.get(q, row)
+	// Como no SQL, comparações com campos ausentes ou nulos são desconhecidas
+	if !foundLeft || !foundRight || left == nil || right == nil {
+		return truthUnknown, nil
+	}
+	if isOrderingOperator(c.op) && (isBool(left) || isBool(right)) {
+		return truthUnknown, fmt.Errorf("o operador %s não se aplica a valores booleanos", c.op)
+	}
+
+	result, ok := compare(left, right)
+	if !ok {
+		return truthFalse, nil
+	}
+	return truthOf(c.holds(result)), nil
+}
+
+// isOrderingOperator indica os operadores que exigem valores ordenáveis
+func isOrderingOperator(op string) bool {
+	switch op {
+	case "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// holds aplica o operador ao resultado de compare
+func (c comparison) holds(result int) bool {
+	switch c.op {
+	case "=":
+		return result == 0
+	case "!=", "<>":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// compare compara numericamente quando um dos lados é número (literal, número JSON ou CAST); o texto do
+// outro lado, como um campo CSV, é convertido e a comparação falha se ele não for numérico. Compara como
+// booleanos quando um lado é booleano (só igualdade: valores diferentes retornam 1, e comparison recusa
+// <, <=, > e >=) e como texto nos demais casos, inclusive entre '007' e um campo CSV "7"
+func compare(left, right any) (int, bool) {
+	if isNumeric(left) || isNumeric(right) {
+		leftNumber, leftIsNumber := toNumber(left)
+		rightNumber, rightIsNumber := toNumber(right)
+		if !leftIsNumber || !rightIsNumber {
+			return 0, false
+		}
+		switch {
+		case leftNumber < rightNumber:
+			return -1, true
+		case leftNumber > rightNumber:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if isBool(left) || isBool(right) {
+		if toString(left) == toString(right) {
+			return 0, true
+		}
+		return 1, true
+	}
+	return strings.Compare(toString(left), toString(right)), true
+}
+
+func isBool(value any) bool {
+	_, ok := value.(bool)
+	return ok
+}
+
+func isNumeric(value any) bool {
+	switch value.(type) {
+	case float64, json.Number:
+		return true
+	}
+	return false
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
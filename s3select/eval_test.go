@@ -0,0 +1,229 @@
+package s3select
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCSV = `nome,codigo,idade,cidade,obs
+Ana,7,30,São Paulo,
+Bruno,007,9,Rio,vip
+Carla,10,45,,vip 100%
+`
+
+const testJSON = `{"nome": "Ana", "idade": 30, "ativo": true, "endereco": {"cidade": "São Paulo"}}
+{"nome": "Bruno", "idade": 9, "ativo": false, "apelido": null}
+{"nome": "Carla", "idade": 45, "ativo": true}
+`
+
+// names executa a consulta e retorna a coluna nome de cada registro selecionado
+func names(t *testing.T, expression string, in Input, data string) []string {
+	t.Helper()
+	q, err := Parse(expression)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expression, err)
+	}
+	result := make([]string, 0)
+	err = q.Run(strings.NewReader(data), in, func(row Row) error {
+		value, _ := row.lookup([]string{"nome"})
+		result = append(result, toString(value))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run(%q): %v", expression, err)
+	}
+	return result
+}
+
+func TestRunCSV(t *testing.T) {
+	in := Input{Format: FormatCSV, CSVHeader: HeaderUse, Delimiter: ","}
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		// Texto entre aspas é comparado como texto com os campos CSV
+		{"s.codigo = '007'", []string{"Bruno"}},
+		{"s.codigo = '7'", []string{"Ana"}},
+		{"s.codigo < '9'", []string{"Ana", "Bruno", "Carla"}},
+		{"s.idade < '9'", []string{"Ana", "Carla"}},
+		// Literais numéricos e CAST convertem o campo
+		{"s.codigo = 7", []string{"Ana", "Bruno"}},
+		{"s.idade < 10", []string{"Bruno"}},
+		{"CAST(s.codigo AS INT) = 7", []string{"Ana", "Bruno"}},
+		{"CAST(s.idade AS INT) > CAST(s.codigo AS INT)", []string{"Ana", "Bruno", "Carla"}},
+		{"CAST(s.nome AS INT) = 0", []string{}},
+		{"s.nome > 10", []string{}},
+		// Precedência: NOT > AND > OR
+		{"s.nome = 'Ana' OR s.nome = 'Bruno' AND s.idade > 40", []string{"Ana"}},
+		{"(s.nome = 'Ana' OR s.nome = 'Bruno') AND s.idade > 20", []string{"Ana"}},
+		{"NOT s.nome = 'Ana' AND s.idade > 20", []string{"Carla"}},
+		{"NOT (s.nome = 'Ana' OR s.idade > 20)", []string{"Bruno"}},
+		{"s.idade >= 30 AND s.idade <= 45 AND s.nome <> 'Carla'", []string{"Ana"}},
+		// LIKE
+		{"s.nome LIKE 'A%'", []string{"Ana"}},
+		{"s.nome LIKE '_runo'", []string{"Bruno"}},
+		{"s.nome NOT LIKE '%a'", []string{"Bruno"}},
+		{"s.obs LIKE '%100.%'", []string{}},
+		{"s.obs LIKE 'vip%'", []string{"Bruno", "Carla"}},
+		// Campos CSV vazios são texto vazio, não nulos; colunas inexistentes são nulas
+		{"s.cidade = ''", []string{"Carla"}},
+		{"s.inexistente IS NULL", []string{"Ana", "Bruno", "Carla"}},
+		{"s.cidade IS NOT NULL", []string{"Ana", "Bruno", "Carla"}},
+		{"s.inexistente = 1 OR s.inexistente != 1", []string{}},
+		{"NOT s.inexistente = 1", []string{}},
+		{"s.inexistente NOT LIKE 'x%'", []string{}},
+		{"s.inexistente = 1 OR s.nome = 'Ana'", []string{"Ana"}},
+		{"NOT (s.inexistente = 1 AND s.nome = 'Ana')", []string{"Bruno", "Carla"}},
+		// Colunas posicionais
+		{"s._3 = '9'", []string{"Bruno"}},
+	}
+
+	for _, tt := range tests {
+		got := names(t, "SELECT * FROM S3Object s WHERE "+tt.where, in, testCSV)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WHERE %s = %v, esperado %v", tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	in := Input{Format: FormatJSON}
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		{"s.idade > 10", []string{"Ana", "Carla"}},
+		{"s.idade = '9'", []string{"Bruno"}},
+		{"s.ativo = true", []string{"Ana", "Carla"}},
+		{"s.ativo != true", []string{"Bruno"}},
+		{"s.endereco.cidade = 'São Paulo'", []string{"Ana"}},
+		{"s.endereco.cidade IS NULL", []string{"Bruno", "Carla"}},
+		{"s.apelido IS NULL", []string{"Ana", "Bruno", "Carla"}},
+		{"s.apelido = 'x' OR NOT s.apelido = 'x'", []string{}},
+		{"S.NOME LIKE '%a'", []string{"Ana", "Carla"}},
+	}
+
+	for _, tt := range tests {
+		got := names(t, "SELECT * FROM S3Object s WHERE "+tt.where, in, testJSON)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WHERE %s = %v, esperado %v", tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestRunProjectionAndLimit(t *testing.T) {
+	q, err := Parse("SELECT s.nome AS n, s.endereco.cidade FROM S3Object s WHERE s.ativo = true LIMIT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]Row, 0)
+	err = q.Run(strings.NewReader(testJSON), Input{Format: FormatJSON}, func(row Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Row{{{Name: "n", Value: "Ana"}, {Name: "cidade", Value: "São Paulo"}}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %v, esperado %v", rows, want)
+	}
+	encoded, err := rows[0].MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"n":"Ana","cidade":"São Paulo"}` {
+		t.Errorf("JSON = %s", encoded)
+	}
+}
+
+func TestRunJSONKeepsFieldOrder(t *testing.T) {
+	tests := []struct {
+		data, want string
+	}{
+		{`{"z": 1, "a": {"y": true, "b": null}, "m": [{"k": 2, "c": "x"}]}`, `{"z":1,"a":{"y":true,"b":null},"m":[{"k":2,"c":"x"}]}`},
+		{`{"b": 1, "a": 2, "b": 3}`, `{"b":3,"a":2}`},
+		{`{}`, `{}`},
+	}
+	for _, tt := range tests {
+		q, err := Parse("SELECT * FROM S3Object")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []byte
+		err = q.Run(strings.NewReader(tt.data), Input{Format: FormatJSON}, func(row Row) error {
+			got, err = row.MarshalJSON()
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Run(%s): %v", tt.data, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("SELECT * em %s = %s, esperado %s", tt.data, got, tt.want)
+		}
+	}
+
+	q, _ := Parse("SELECT * FROM S3Object")
+	if err := q.Run(strings.NewReader(`[1, 2]`), Input{Format: FormatJSON}, func(Row) error { return nil }); err == nil {
+		t.Error("Run com registro que não é objeto: erro esperado")
+	}
+}
+
+func TestRunBoolOrderingError(t *testing.T) {
+	for _, where := range []string{"s.ativo < 'x'", "s.idade > 1 AND s.ativo >= 1", "NOT s.ativo <= s.nome"} {
+		q, err := Parse("SELECT * FROM S3Object s WHERE " + where)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", where, err)
+		}
+		err = q.Run(strings.NewReader(testJSON), Input{Format: FormatJSON}, func(Row) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "booleanos") {
+			t.Errorf("WHERE %s: erro de tipo esperado, obtido %v", where, err)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		left, right any
+		want        int
+		ok          bool
+	}{
+		{"007", "7", -1, true},
+		{"10", "9", -1, true},
+		{"10", 9.0, 1, true},
+		{7.0, "007", 0, true},
+		{"abc", 1.0, 0, false},
+		{true, "true", 0, true},
+		{false, true, 1, true},
+		{"b", "a", 1, true},
+	}
+	for _, tt := range tests {
+		got, ok := compare(tt.left, tt.right)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("compare(%#v, %#v) = %d, %v; esperado %d, %v", tt.left, tt.right, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"a%", "abc", true},
+		{"a%", "bac", false},
+		{"a_c", "abc", true},
+		{"a_c", "abbc", false},
+		{"%.txt", "arquivo.txt", true},
+		{"%.txt", "arquivoXtxt", false},
+		{"(a)+", "(a)+", true},
+		{"%", "linha\nnova", true},
+	}
+	for _, tt := range tests {
+		if got := likePattern(tt.pattern).MatchString(tt.value); got != tt.want {
+			t.Errorf("LIKE %q com %q = %v, esperado %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package s3select
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query é a forma interpretada de um SELECT simples:
+//
+//	SELECT <*|campo [AS nome], ...> FROM S3Object [alias] [WHERE condição] [LIMIT n]
+//
+// A condição aceita comparações (=, !=, <>, <, <=, >, >=), LIKE, IS [NOT] NULL,
+// AND, OR, NOT, parênteses e CAST(operando AS INT|INTEGER|FLOAT|DECIMAL|NUMERIC|STRING).
+// Como no S3 Select, campos CSV são texto: comparados com um texto entre aspas simples,
+// a comparação é textual; para compará-los como números use um literal numérico ou CAST.
+// As demais funções e agregações não são suportadas.
+type Query struct {
+	fields []projection
+	alias  string
+	where  condition
+	// -1 quando a consulta não tem LIMIT
+	limit int
+}
+
+type projection struct {
+	path []string
+	name string
+}
+
+func Parse(expression string) (*Query, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("trecho não suportado na expressão: %s", p.peek().text)
+	}
+	return q, nil
+}
+
+type tokenKind int
+
+const (
+	// tokenEnd é o token vazio retornado após o fim da expressão
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(expression string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(expression); {
+		ch := expression[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '\'' || ch == '"':
+			// Aspas simples delimitam texto e aspas duplas delimitam nomes; aspas repetidas escapam
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(expression) {
+					return nil, fmt.Errorf("aspas não fechadas na expressão")
+				}
+				if expression[j] == ch {
+					if j+1 < len(expression) && expression[j+1] == ch {
+						b.WriteByte(ch)
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(expression[j])
+				j++
+			}
+			kind := tokenString
+			if ch == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: b.String()})
+			i = j + 1
+		case isDigit(ch) || (ch == '-' && i+1 < len(expression) && isDigit(expression[i+1])):
+			j := i + 1
+			for j < len(expression) && (isDigit(expression[j]) || expression[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[i:j]})
+			i = j
+		case isIdentStart(ch):
+			j := i + 1
+			for j < len(expression) && (isIdentStart(expression[j]) || isDigit(expression[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[i:j]})
+			i = j
+		case strings.HasPrefix(expression[i:], "<=") || strings.HasPrefix(expression[i:], ">=") ||
+			strings.HasPrefix(expression[i:], "<>") || strings.HasPrefix(expression[i:], "!="):
+			tokens = append(tokens, token{kind: tokenSymbol, text: expression[i : i+2]})
+			i += 2
+		case strings.ContainsRune("=<>(),.*", rune(ch)):
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(ch)})
+			i++
+		default:
+			return nil, fmt.Errorf("caractere inesperado na expressão: %q", ch)
+		}
+	}
+	return tokens, nil
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+// next retorna o token seguinte ao atual sem consumir nenhum dos dois
+func (p *parser) next() token {
+	if p.pos+1 >= len(p.tokens) {
+		return token{}
+	}
+	return p.tokens[p.pos+1]
+}
+
+// keyword consome a palavra-chave informada, se ela for o próximo token
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) symbol(text string) bool {
+	if t := p.peek(); t.kind == tokenSymbol && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+var reservedWords = map[string]bool{
+	"select": true, "from": true, "where": true, "limit": true, "as": true,
+	"and": true, "or": true, "not": true, "like": true, "is": true, "null": true,
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	if !p.keyword("select") {
+		return nil, fmt.Errorf("a expressão deve começar com SELECT")
+	}
+
+	q := &Query{limit: -1}
+	if !p.symbol("*") {
+		for {
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			field := projection{path: path, name: path[len(path)-1]}
+			if p.keyword("as") {
+				t := p.peek()
+				if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+					return nil, fmt.Errorf("nome esperado após AS")
+				}
+				field.name = t.text
+				p.pos++
+			}
+			q.fields = append(q.fields, field)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+
+	if !p.keyword("from") {
+		return nil, fmt.Errorf("FROM esperado")
+	}
+	if t := p.peek(); t.kind != tokenIdent || !strings.EqualFold(t.text, "s3object") {
+		return nil, fmt.Errorf("apenas FROM S3Object é suportado")
+	}
+	p.pos++
+	p.keyword("as")
+	if t := p.peek(); t.kind == tokenIdent && !reservedWords[strings.ToLower(t.text)] {
+		q.alias = t.text
+		p.pos++
+	}
+
+	if p.keyword("where") {
+		where, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		q.where = where
+	}
+
+	if p.keyword("limit") {
+		t := p.peek()
+		limit, err := strconv.Atoi(t.text)
+		if t.kind != tokenNumber || err != nil || limit < 0 {
+			return nil, fmt.Errorf("LIMIT deve ser um número inteiro não negativo")
+		}
+		q.limit = limit
+		p.pos++
+	}
+	return q, nil
+}
+
+// parsePath lê uma referência a campo como s.nome, s."nome com espaço" ou s.endereco.cidade
+func (p *parser) parsePath() ([]string, error) {
+	path := make([]string, 0)
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenQuotedIdent:
+		case t.kind == tokenIdent && !reservedWords[strings.ToLower(t.text)]:
+		default:
+			return nil, fmt.Errorf("nome de campo esperado")
+		}
+		path = append(path, t.text)
+		p.pos++
+		if !p.symbol(".") {
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	if p.keyword("not") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{inner}, nil
+	}
+	if p.symbol("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.symbol(")") {
+			return nil, fmt.Errorf("parêntese não fechado")
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (condition, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.keyword("is") {
+		negated := p.keyword("not")
+		if !p.keyword("null") {
+			return nil, fmt.Errorf("NULL esperado após IS")
+		}
+		return nullCondition{operand: left, negated: negated}, nil
+	}
+
+	negated := p.keyword("not")
+	if p.keyword("like") {
+		t := p.peek()
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("LIKE exige um texto entre aspas simples")
+		}
+		p.pos++
+		return likeCondition{operand: left, pattern: likePattern(t.text), negated: negated}, nil
+	}
+	if negated {
+		return nil, fmt.Errorf("LIKE esperado após NOT")
+	}
+
+	t := p.peek()
+	if t.kind != tokenSymbol || !isComparisonOperator(t.text) {
+		return nil, fmt.Errorf("operador de comparação esperado")
+	}
+	p.pos++
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if isOrderingOperator(t.text) && (isBoolLiteral(left) || isBoolLiteral(right)) {
+		return nil, fmt.Errorf("o operador %s não se aplica a valores booleanos", t.text)
+	}
+	return comparison{left: left, op: t.text, right: right}, nil
+}
+
+func isBoolLiteral(o operand) bool {
+	l, ok := o.(literal)
+	return ok && isBool(l.value)
+}
+
+func isComparisonOperator(op string) bool {
+	switch op {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenString:
+		p.pos++
+		return literal{value: t.text}, nil
+	case t.kind == tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido: %s", t.text)
+		}
+		p.pos++
+		return literal{value: n}, nil
+	case t.kind == tokenIdent && (strings.EqualFold(t.text, "true") || strings.EqualFold(t.text, "false")):
+		p.pos++
+		return literal{value: strings.EqualFold(t.text, "true")}, nil
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "cast") && p.next().text == "(":
+		return p.parseCast()
+	}
+
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	return field{path: path}, nil
+}
+
+var castTypes = map[string]bool{
+	"int": true, "integer": true, "float": true, "decimal": true, "numeric": true, "string": true,
+}
+
+// parseCast lê CAST(operando AS tipo)
+func (p *parser) parseCast() (operand, error) {
+	p.pos += 2
+	inner, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword("as") {
+		return nil, fmt.Errorf("AS esperado no CAST")
+	}
+	t := p.peek()
+	typeName := strings.ToLower(t.text)
+	if t.kind != tokenIdent || !castTypes[typeName] {
+		return nil, fmt.Errorf("tipo não suportado no CAST: %s", t.text)
+	}
+	p.pos++
+	if !p.symbol(")") {
+		return nil, fmt.Errorf("parêntese não fechado no CAST")
+	}
+	return castOperand{inner: inner, typeName: typeName}, nil
+}
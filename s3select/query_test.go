@@ -0,0 +1,123 @@
+package s3select
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []token
+	}{
+		{
+			expression: "SELECT * FROM S3Object",
+			want: []token{
+				{tokenIdent, "SELECT"}, {tokenSymbol, "*"}, {tokenIdent, "FROM"}, {tokenIdent, "S3Object"},
+			},
+		},
+		{
+			expression: `s."nome completo" <> 'it''s'`,
+			want: []token{
+				{tokenIdent, "s"}, {tokenSymbol, "."}, {tokenQuotedIdent, "nome completo"},
+				{tokenSymbol, "<>"}, {tokenString, "it's"},
+			},
+		},
+		{
+			expression: "s.preco>=-10.5 AND s.qtd!=3",
+			want: []token{
+				{tokenIdent, "s"}, {tokenSymbol, "."}, {tokenIdent, "preco"}, {tokenSymbol, ">="}, {tokenNumber, "-10.5"},
+				{tokenIdent, "AND"}, {tokenIdent, "s"}, {tokenSymbol, "."}, {tokenIdent, "qtd"}, {tokenSymbol, "!="}, {tokenNumber, "3"},
+			},
+		},
+		{
+			expression: "(a<=b)",
+			want: []token{
+				{tokenSymbol, "("}, {tokenIdent, "a"}, {tokenSymbol, "<="}, {tokenIdent, "b"}, {tokenSymbol, ")"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tokenize(tt.expression)
+		if err != nil {
+			t.Errorf("tokenize(%q): erro inesperado: %v", tt.expression, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, esperado %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, expression := range []string{
+		"SELECT * FROM S3Object s WHERE s.nome = 'aberto",
+		`SELECT "campo FROM S3Object`,
+		"SELECT * FROM S3Object WHERE s.a ; s.b",
+	} {
+		if _, err := tokenize(expression); err == nil {
+			t.Errorf("tokenize(%q): erro esperado", expression)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expression string
+		fields     []projection
+		alias      string
+		limit      int
+	}{
+		{"SELECT * FROM S3Object", nil, "", -1},
+		{"select * from s3object s limit 10", nil, "s", 10},
+		{"SELECT * FROM S3Object AS t WHERE t.a = 1", nil, "t", -1},
+		{
+			`SELECT s.nome, s.endereco.cidade AS cidade, s."valor total" FROM S3Object s LIMIT 0`,
+			[]projection{
+				{path: []string{"s", "nome"}, name: "nome"},
+				{path: []string{"s", "endereco", "cidade"}, name: "cidade"},
+				{path: []string{"s", "valor total"}, name: "valor total"},
+			},
+			"s", 0,
+		},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.expression)
+		if err != nil {
+			t.Errorf("Parse(%q): erro inesperado: %v", tt.expression, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.fields, tt.fields) || q.alias != tt.alias || q.limit != tt.limit {
+			t.Errorf("Parse(%q) = campos %v, alias %q, limit %d; esperado %v, %q, %d",
+				tt.expression, q.fields, q.alias, q.limit, tt.fields, tt.alias, tt.limit)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"DELETE FROM S3Object",
+		"SELECT *",
+		"SELECT * FROM tabela",
+		"SELECT * FROM S3Object s WHERE",
+		"SELECT * FROM S3Object s WHERE s.a",
+		"SELECT * FROM S3Object s WHERE (s.a = 1",
+		"SELECT * FROM S3Object s WHERE s.a IS 1",
+		"SELECT * FROM S3Object s WHERE s.a NOT = 1",
+		"SELECT * FROM S3Object s WHERE s.a LIKE s.b",
+		"SELECT * FROM S3Object s WHERE CAST(s.a AS DATE) = 1",
+		"SELECT * FROM S3Object s WHERE CAST(s.a INT) = 1",
+		"SELECT * FROM S3Object s WHERE s.ativo > true",
+		"SELECT * FROM S3Object s WHERE false <= s.ativo",
+		"SELECT * FROM S3Object s LIMIT -1",
+		"SELECT * FROM S3Object s LIMIT 10 ORDER",
+		"SELECT COUNT(*) FROM S3Object",
+	} {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("Parse(%q): erro esperado", expression)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package s3select
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Tratamento da primeira linha do CSV, com os mesmos nomes do FileHeaderInfo do S3 Select
+const (
+	HeaderUse    = "USE"
+	HeaderIgnore = "IGNORE"
+	HeaderNone   = "NONE"
+)
+
+// Input descreve o formato do objeto consultado
+type Input struct {
+	Format    string
+	CSVHeader string
+	Delimiter string
+}
+
+func (in Input) Validate() error {
+	switch in.Format {
+	case FormatJSON:
+		return nil
+	case FormatCSV:
+	default:
+		return fmt.Errorf("formato inválido: %s (use csv ou json)", in.Format)
+	}
+
+	switch in.CSVHeader {
+	case HeaderUse, HeaderIgnore, HeaderNone:
+	default:
+		return fmt.Errorf("csv_header inválido: %s (use USE, IGNORE ou NONE)", in.CSVHeader)
+	}
+	if utf8.RuneCountInString(in.Delimiter) != 1 {
+		return fmt.Errorf("o delimitador deve ter um único caractere")
+	}
+	return nil
+}
+
+// errLimitReached interrompe a leitura quando o LIMIT da consulta é atingido
+var errLimitReached = errors.New("limite atingido")
+
+// Run lê os registros de r, aplica o WHERE e a projeção e entrega cada resultado a emit, na ordem do objeto
+func (q *Query) Run(r io.Reader, in Input, emit func(Row) error) error {
+	if err := in.Validate(); err != nil {
+		return err
+	}
+
+	emitted := 0
+	handle := func(row Row) error {
+		if q.limit >= 0 && emitted >= q.limit {
+			return errLimitReached
+		}
+		matched, err := q.Match(row)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return nil
+		}
+		emitted++
+		return emit(q.Project(row))
+	}
+
+	var err error
+	if in.Format == FormatCSV {
+		err = readCSV(r, in, handle)
+	} else {
+		err = readJSONLines(r, handle)
+	}
+	if errors.Is(err, errLimitReached) {
+		return nil
+	}
+	return err
+}
+
+func readCSV(r io.Reader, in Input, handle func(Row) error) error {
+	reader := csv.NewReader(r)
+	reader.Comma, _ = utf8.DecodeRuneInString(in.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var header []string
+	if in.CSVHeader != HeaderNone {
+		first, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler CSV: %v", err)
+		}
+		if in.CSVHeader == HeaderUse {
+			header = append([]string(nil), first...)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler CSV: %v", err)
+		}
+
+		// Colunas sem nome no cabeçalho recebem o nome posicional (_1, _2, ...)
+		row := make(Row, len(record))
+		for i, value := range record {
+			name := "_" + strconv.Itoa(i+1)
+			if i < len(header) && header[i] != "" {
+				name = header[i]
+			}
+			row[i] = Column{Name: name, Value: value}
+		}
+		if err := handle(row); err != nil {
+			return err
+		}
+	}
+}
+
+// readJSONLines lê uma sequência de objetos JSON (um por linha, como no tipo LINES do S3 Select),
+// mantendo os campos na ordem do documento
+func readJSONLines(r io.Reader, handle func(Row) error) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler JSON: %v", err)
+		}
+		if token != json.Delim('{') {
+			return fmt.Errorf("erro ao ler JSON: cada registro deve ser um objeto")
+		}
+
+		row, err := decodeObject(decoder)
+		if err != nil {
+			return fmt.Errorf("erro ao ler JSON: %v", err)
+		}
+		if err := handle(row); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeObject lê os campos de um objeto cujo '{' já foi consumido; objetos aninhados também viram Row
+// para manter a ordem na saída. Como no encoding/json, um campo repetido fica com o último valor
+func decodeObject(decoder *json.Decoder) (Row, error) {
+	row := make(Row, 0)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+		value, err := decodeValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		if i := row.index(name); i >= 0 {
+			row[i].Value = value
+			continue
+		}
+		row = append(row, Column{Name: name, Value: value})
+	}
+	// Fecha o objeto ('}')
+	_, err := decoder.Token()
+	return row, err
+}
+
+func decodeValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		return decodeObject(decoder)
+	case json.Delim('['):
+		values := make([]any, 0)
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		_, err := decoder.Token()
+		return values, err
+	}
+	return token, nil
+}
@@ -0,0 +1,385 @@
+package s3sync
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type Direction string
+
+const (
+	// Upload espelha o diretório local no prefixo do bucket
+	Upload Direction = "upload"
+	// Download espelha o prefixo do bucket no diretório local
+	Download Direction = "download"
+)
+
+const defaultConcurrency = 4
+
+type Options struct {
+	LocalDir    string
+	Bucket      string
+	Prefix      string
+	Direction   Direction
+	Delete      bool
+	DryRun      bool
+	Concurrency int
+}
+
+type Action struct {
+	Type   string `json:"type"`
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Result struct {
+	Actions []Action `json:"actions"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+type fileInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	ETag    string
+}
+
+type Syncer struct {
+	client *s3.Client
+}
+
+func New(client *s3.Client) *Syncer {
+	return &Syncer{client: client}
+}
+
+// normalizePrefix remove a barra inicial e garante a barra final em prefixos não vazios
+func normalizePrefix(prefix string) string {
+	prefix = strings.TrimPrefix(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+func (s *Syncer) Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Direction != Upload && opts.Direction != Download {
+		return nil, fmt.Errorf("direção inválida: %s", opts.Direction)
+	}
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("bucket é obrigatório")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	opts.Prefix = normalizePrefix(opts.Prefix)
+
+	if opts.Direction == Download {
+		if err := os.MkdirAll(opts.LocalDir, 0o755); err != nil {
+			return nil, fmt.Errorf("erro ao criar diretório local: %v", err)
+		}
+	}
+
+	local, err := listLocal(opts.LocalDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diretório local: %v", err)
+	}
+	remote, err := s.listRemote(ctx, opts.Bucket, opts.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar objetos: %v", err)
+	}
+
+	actions, skipped, err := plan(opts, local, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Actions: actions, Skipped: skipped, DryRun: opts.DryRun}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	s.execute(ctx, opts, result.Actions)
+	for _, action := range result.Actions {
+		if action.Error != "" {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// listLocal retorna os arquivos do diretório indexados pelo caminho relativo com barras
+func listLocal(dir string) (map[string]fileInfo, error) {
+	files := make(map[string]fileInfo)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = fileInfo{Path: p, Size: info.Size(), ModTime: info.ModTime()}
+		return nil
+	})
+	return files, err
+}
+
+// listRemote retorna os objetos do prefixo indexados pela chave sem o prefixo
+func (s *Syncer) listRemote(ctx context.Context, bucket, prefix string) (map[string]fileInfo, error) {
+	objects := make(map[string]fileInfo)
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			rel := strings.TrimPrefix(key, prefix)
+			// Marcadores de "pasta" não têm arquivo correspondente
+			if rel == "" || strings.HasSuffix(rel, "/") {
+				continue
+			}
+			objects[rel] = fileInfo{
+				Path:    key,
+				Size:    aws.ToInt64(object.Size),
+				ModTime: aws.ToTime(object.LastModified),
+				ETag:    strings.Trim(aws.ToString(object.ETag), `"`),
+			}
+		}
+	}
+	return objects, nil
+}
+
+// comparableETag indica se o ETag é o MD5 do conteúdo (não vale para uploads multipart)
+func comparableETag(etag string) bool {
+	return len(etag) == 32 && !strings.Contains(etag, "-")
+}
+
+func fileMD5(p string) (string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// changeReason compara tamanho, ETag e data de modificação e retorna o motivo da transferência ou vazio
+func changeReason(direction Direction, local, remote fileInfo) (string, error) {
+	if local.Size != remote.Size {
+		return "tamanho diferente", nil
+	}
+	if comparableETag(remote.ETag) {
+		sum, err := fileMD5(local.Path)
+		if err != nil {
+			return "", err
+		}
+		if sum != remote.ETag {
+			return "conteúdo diferente", nil
+		}
+		return "", nil
+	}
+	// Sem ETag comparável, a cópia mais recente prevalece
+	if direction == Upload && local.ModTime.After(remote.ModTime) {
+		return "arquivo local mais recente", nil
+	}
+	if direction == Download && remote.ModTime.After(local.ModTime) {
+		return "objeto remoto mais recente", nil
+	}
+	return "", nil
+}
+
+func plan(opts Options, local, remote map[string]fileInfo) ([]Action, int, error) {
+	actions := make([]Action, 0)
+	skipped := 0
+
+	source, target := local, remote
+	if opts.Direction == Download {
+		source, target = remote, local
+	}
+
+	names := make([]string, 0, len(source))
+	for name := range source {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := opts.Prefix + name
+		localPath := filepath.Join(opts.LocalDir, filepath.FromSlash(name))
+		if opts.Direction == Download && !filepath.IsLocal(filepath.FromSlash(name)) {
+			actions = append(actions, Action{Type: string(Download), Key: key, Path: localPath, Error: "chave aponta para fora do diretório local"})
+			continue
+		}
+
+		existing, found := target[name]
+		reason := "arquivo novo"
+		if found {
+			localInfo, remoteInfo := source[name], existing
+			if opts.Direction == Download {
+				localInfo, remoteInfo = existing, source[name]
+			}
+			var err error
+			reason, err = changeReason(opts.Direction, localInfo, remoteInfo)
+			if err != nil {
+				return nil, 0, fmt.Errorf("erro ao comparar %s: %v", name, err)
+			}
+			if reason == "" {
+				skipped++
+				continue
+			}
+		}
+		actions = append(actions, Action{Type: string(opts.Direction), Key: key, Path: localPath, Reason: reason})
+	}
+
+	if opts.Delete {
+		extraneous := make([]string, 0)
+		for name := range target {
+			if _, found := source[name]; !found {
+				extraneous = append(extraneous, name)
+			}
+		}
+		sort.Strings(extraneous)
+		for _, name := range extraneous {
+			actions = append(actions, Action{
+				Type:   "delete",
+				Key:    opts.Prefix + name,
+				Path:   filepath.Join(opts.LocalDir, filepath.FromSlash(name)),
+				Reason: "não existe na origem",
+			})
+		}
+	}
+	return actions, skipped, nil
+}
+
+// execute processa as ações em paralelo, limitado por opts.Concurrency
+func (s *Syncer) execute(ctx context.Context, opts Options, actions []Action) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				if actions[index].Error != "" {
+					continue
+				}
+				if err := s.apply(ctx, opts, actions[index]); err != nil {
+					actions[index].Error = err.Error()
+				}
+			}
+		}()
+	}
+
+	for index := range actions {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func (s *Syncer) apply(ctx context.Context, opts Options, action Action) error {
+	switch {
+	case action.Type == string(Upload):
+		return s.upload(ctx, opts.Bucket, action.Key, action.Path)
+	case action.Type == string(Download):
+		return s.download(ctx, opts.Bucket, action.Key, action.Path)
+	case action.Type == "delete" && opts.Direction == Upload:
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(opts.Bucket),
+			Key:    aws.String(action.Key),
+		})
+		return err
+	case action.Type == "delete" && opts.Direction == Download:
+		return os.Remove(action.Path)
+	}
+	return fmt.Errorf("ação desconhecida: %s", action.Type)
+}
+
+func (s *Syncer) upload(ctx context.Context, bucket, key, localPath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   file,
+	})
+	return err
+}
+
+func (s *Syncer) download(ctx context.Context, bucket, key, localPath string) error {
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer result.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+
+	// Gravar em arquivo temporário para não deixar arquivos parciais em caso de erro
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+path.Base(key)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, result.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		return err
+	}
+
+	// Alinhar a data de modificação com o objeto para que a próxima comparação não o considere alterado
+	if result.LastModified != nil {
+		return os.Chtimes(localPath, *result.LastModified, *result.LastModified)
+	}
+	return nil
+}
@@ -0,0 +1,528 @@
+package sqsconsumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
+)
+
+// Limites do SQS usados pelo consumidor
+const (
+	maxBatchSize         = 10
+	maxWaitTimeSeconds   = 20
+	maxVisibilityTimeout = 43200
+)
+
+// Espera antes de tentar novamente quando a fila não pode ser lida
+const pollErrorDelay = 5 * time.Second
+
+// Atributo com as tentativas anteriores de uma mensagem reenviada após ser interrompida no encerramento,
+// que o reenvio tira do ApproximateReceiveCount
+const attemptsAttribute = "sqsconsumer-attempts"
+
+// Limite de atributos de mensagem do SQS
+const maxMessageAttributes = 10
+
+// Handler processa uma mensagem; retornar nil confirma a mensagem e qualquer erro a devolve à fila
+type Handler func(ctx context.Context, message Message) error
+
+// Message é a mensagem recebida, com o número da tentativa atual (1 na primeira entrega)
+type Message struct {
+	types.Message
+	Queue   string
+	Attempt int
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marca o erro como definitivo: a mensagem é tratada como poison sem novas tentativas
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+type retryError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e retryError) Error() string { return e.err.Error() }
+func (e retryError) Unwrap() error { return e.err }
+
+// RetryAfter devolve a mensagem à fila com o atraso informado, em vez do calculado pelo Backoff
+func RetryAfter(err error, delay time.Duration) error {
+	return retryError{err: err, delay: delay}
+}
+
+type Options struct {
+	// Mensagens processadas ao mesmo tempo
+	Concurrency int
+	// Segundos de long polling de cada ReceiveMessage (até 20)
+	WaitTimeSeconds int32
+	// Prazo de processamento concedido a cada recebimento e a cada renovação
+	VisibilityTimeout time.Duration
+	// Intervalo de renovação da visibilidade enquanto o handler executa; o padrão é metade do VisibilityTimeout
+	HeartbeatInterval time.Duration
+	// Tentativas antes de a mensagem ser tratada como poison
+	MaxAttempts int
+	// Atraso antes da próxima tentativa; o padrão é exponencial, de 1s até 5min
+	Backoff func(attempt int) time.Duration
+	// Fila que recebe as mensagens poison; vazia descarta a mensagem após registrá-la no log
+	PoisonQueue string
+}
+
+func (o Options) withDefaults() Options {
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+	if o.WaitTimeSeconds <= 0 || o.WaitTimeSeconds > maxWaitTimeSeconds {
+		o.WaitTimeSeconds = maxWaitTimeSeconds
+	}
+	if o.VisibilityTimeout < time.Second {
+		o.VisibilityTimeout = 30 * time.Second
+	}
+	if o.HeartbeatInterval <= 0 || o.HeartbeatInterval >= o.VisibilityTimeout {
+		o.HeartbeatInterval = o.VisibilityTimeout / 2
+	}
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 5
+	}
+	if o.Backoff == nil {
+		o.Backoff = ExponentialBackoff(time.Second, 5*time.Minute)
+	}
+	return o
+}
+
+// ExponentialBackoff dobra o atraso a cada tentativa, começando em base e limitado a max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		return min(delay, max)
+	}
+}
+
+// Estados do consumidor
+const (
+	StateIdle     = "idle"
+	StateRunning  = "running"
+	StateDraining = "draining"
+	StateStopped  = "stopped"
+)
+
+// Stats são os contadores do consumidor; AckFailed conta as mensagens processadas com sucesso que não
+// puderam ser removidas da fila e serão entregues novamente, e Interrupted as que tiveram o handler
+// cancelado no encerramento e voltaram à fila sem contar como falha
+type Stats struct {
+	Queue       string     `json:"queue"`
+	State       string     `json:"state"`
+	Concurrency int        `json:"concurrency"`
+	InFlight    int        `json:"in_flight"`
+	Processed   int64      `json:"processed"`
+	AckFailed   int64      `json:"ack_failed"`
+	Failed      int64      `json:"failed"`
+	Retried     int64      `json:"retried"`
+	Poisoned    int64      `json:"poisoned"`
+	Interrupted int64      `json:"interrupted"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+}
+
+// Consumer lê uma fila e despacha as mensagens ao handler, com no máximo Concurrency em processamento
+type Consumer struct {
+	client  *sqs.Client
+	queue   string
+	handler Handler
+	opts    Options
+
+	queueURL       string
+	poisonQueueURL string
+
+	// Slots livres para novas mensagens; o poller só recebe o que pode processar
+	slots chan struct{}
+
+	stopPolling    context.CancelFunc
+	cancelHandlers context.CancelFunc
+	pollerDone     chan struct{}
+	handlers       sync.WaitGroup
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+func newConsumer(client *sqs.Client, queue string, handler Handler, opts Options) *Consumer {
+	opts = opts.withDefaults()
+	return &Consumer{
+		client:     client,
+		queue:      queue,
+		handler:    handler,
+		opts:       opts,
+		slots:      make(chan struct{}, opts.Concurrency),
+		pollerDone: make(chan struct{}),
+		stats: Stats{
+			Queue:       queue,
+			State:       StateIdle,
+			Concurrency: opts.Concurrency,
+		},
+	}
+}
+
+func (c *Consumer) start() {
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+	c.stopPolling, c.cancelHandlers = stopPolling, cancelHandlers
+
+	c.update(func(stats *Stats) {
+		stats.State = StateRunning
+		stats.StartedAt = time.Now()
+	})
+	go c.poll(pollCtx, handlerCtx)
+}
+
+// Stats retorna uma cópia do estado atual do consumidor
+func (c *Consumer) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *Consumer) update(apply func(stats *Stats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	apply(&c.stats)
+}
+
+func (c *Consumer) recordError(err error) {
+	c.update(func(stats *Stats) {
+		now := time.Now()
+		stats.LastError = err.Error()
+		stats.LastErrorAt = &now
+	})
+}
+
+// sleep espera o intervalo ou até o contexto ser cancelado
+func sleep(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (c *Consumer) resolveQueueURLs(ctx context.Context) error {
+	if c.queueURL == "" {
+		output, err := c.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(c.queue)})
+		if err != nil {
+			return fmt.Errorf("erro ao localizar fila %s: %v", c.queue, err)
+		}
+		c.queueURL = *output.QueueUrl
+	}
+	if c.opts.PoisonQueue != "" && c.poisonQueueURL == "" {
+		output, err := c.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(c.opts.PoisonQueue)})
+		if err != nil {
+			return fmt.Errorf("erro ao localizar fila poison %s: %v", c.opts.PoisonQueue, err)
+		}
+		c.poisonQueueURL = *output.QueueUrl
+	}
+	return nil
+}
+
+// acquire reserva ao menos um slot, aguardando se necessário, e até max sem esperar
+func (c *Consumer) acquire(ctx context.Context, max int) int {
+	select {
+	case <-ctx.Done():
+		return 0
+	case c.slots <- struct{}{}:
+	}
+	acquired := 1
+	for acquired < max {
+		select {
+		case c.slots <- struct{}{}:
+			acquired++
+		default:
+			return acquired
+		}
+	}
+	return acquired
+}
+
+func (c *Consumer) release(n int) {
+	for i := 0; i < n; i++ {
+		<-c.slots
+	}
+}
+
+func (c *Consumer) poll(pollCtx, handlerCtx context.Context) {
+	defer close(c.pollerDone)
+
+	for pollCtx.Err() == nil {
+		if err := c.resolveQueueURLs(pollCtx); err != nil {
+			if pollCtx.Err() == nil {
+				log.Printf("Consumidor SQS %s: %v", c.queue, err)
+				c.recordError(err)
+				sleep(pollCtx, pollErrorDelay)
+			}
+			continue
+		}
+
+		slots := c.acquire(pollCtx, min(c.opts.Concurrency, maxBatchSize))
+		if slots == 0 {
+			return
+		}
+
+		output, err := c.client.ReceiveMessage(pollCtx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(c.queueURL),
+			MaxNumberOfMessages:   int32(slots),
+			WaitTimeSeconds:       c.opts.WaitTimeSeconds,
+			VisibilityTimeout:     int32(c.opts.VisibilityTimeout / time.Second),
+			AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			c.release(slots)
+			if pollCtx.Err() == nil {
+				log.Printf("Consumidor SQS %s: erro ao receber mensagens: %v", c.queue, err)
+				c.recordError(err)
+				sleep(pollCtx, pollErrorDelay)
+			}
+			continue
+		}
+
+		c.release(slots - len(output.Messages))
+		for _, message := range output.Messages {
+			c.handlers.Add(1)
+			go c.process(handlerCtx, message)
+		}
+	}
+}
+
+// process executa o handler renovando a visibilidade e decide entre confirmar, repetir ou tratar como poison
+func (c *Consumer) process(ctx context.Context, message types.Message) {
+	defer c.handlers.Done()
+	defer c.release(1)
+
+	c.update(func(stats *Stats) { stats.InFlight++ })
+	defer c.update(func(stats *Stats) { stats.InFlight-- })
+
+	attempt := attemptOf(message)
+
+	handlerCtx, cancel := context.WithCancel(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		c.heartbeat(handlerCtx, message)
+	}()
+
+	err := c.call(handlerCtx, Message{Message: message, Queue: c.queue, Attempt: attempt})
+	cancel()
+	<-heartbeatDone
+
+	// As chamadas finais não dependem do contexto dos handlers, cancelado no fim do drain
+	finalCtx := context.WithoutCancel(ctx)
+	if err == nil {
+		if err := c.delete(finalCtx, message); err != nil {
+			log.Printf("Consumidor SQS %s: erro ao confirmar mensagem %s: %v", c.queue, aws.ToString(message.MessageId), err)
+			c.recordError(err)
+			c.update(func(stats *Stats) { stats.AckFailed++ })
+			return
+		}
+		c.update(func(stats *Stats) { stats.Processed++ })
+		return
+	}
+
+	// Um handler cancelado pelo encerramento não falhou: a mensagem volta à fila na hora, sem backoff e sem
+	// ser tratada como poison, para que reinícios seguidos não esgotem as tentativas de uma mensagem saudável
+	if ctx.Err() != nil {
+		c.interrupted(finalCtx, message, attempt)
+		return
+	}
+
+	c.recordError(err)
+	c.update(func(stats *Stats) { stats.Failed++ })
+
+	var permanent permanentError
+	if errors.As(err, &permanent) || attempt >= c.opts.MaxAttempts {
+		c.poison(finalCtx, message, attempt, err)
+		return
+	}
+
+	delay := c.opts.Backoff(attempt)
+	var retry retryError
+	if errors.As(err, &retry) {
+		delay = retry.delay
+	}
+	c.retry(finalCtx, message, delay)
+}
+
+// attemptOf soma os recebimentos da mensagem às tentativas trazidas de um reenvio anterior
+func attemptOf(message types.Message) int {
+	attempt, _ := strconv.Atoi(message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+	if previous, ok := message.MessageAttributes[attemptsAttribute]; ok {
+		carried, _ := strconv.Atoi(aws.ToString(previous.StringValue))
+		attempt += max(carried, 0)
+	}
+	return max(attempt, 1)
+}
+
+// withoutAttempts copia os atributos da mensagem sem o contador interno de tentativas
+func withoutAttempts(attributes map[string]types.MessageAttributeValue) map[string]types.MessageAttributeValue {
+	if _, ok := attributes[attemptsAttribute]; !ok {
+		return attributes
+	}
+	copied := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		if name != attemptsAttribute {
+			copied[name] = value
+		}
+	}
+	return copied
+}
+
+// call executa o handler convertendo panics em erro
+func (c *Consumer) call(ctx context.Context, message Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic no handler: %v", r)
+		}
+	}()
+	return c.handler(ctx, message)
+}
+
+// heartbeat estende a visibilidade da mensagem enquanto o handler não termina
+func (c *Consumer) heartbeat(ctx context.Context, message types.Message) {
+	ticker := time.NewTicker(c.opts.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          aws.String(c.queueURL),
+				ReceiptHandle:     message.ReceiptHandle,
+				VisibilityTimeout: int32(c.opts.VisibilityTimeout / time.Second),
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Consumidor SQS %s: erro ao renovar visibilidade de %s: %v", c.queue, aws.ToString(message.MessageId), err)
+			}
+		}
+	}
+}
+
+func (c *Consumer) delete(ctx context.Context, message types.Message) error {
+	_, err := c.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(c.queueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	return err
+}
+
+// retry devolve a mensagem à fila, visível novamente após o atraso
+func (c *Consumer) retry(ctx context.Context, message types.Message, delay time.Duration) {
+	timeout := min(int32(delay/time.Second), maxVisibilityTimeout)
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(c.queueURL),
+		ReceiptHandle:     message.ReceiptHandle,
+		VisibilityTimeout: max(timeout, 0),
+	})
+	if err != nil {
+		log.Printf("Consumidor SQS %s: erro ao devolver mensagem %s: %v", c.queue, aws.ToString(message.MessageId), err)
+		c.recordError(err)
+	}
+	c.update(func(stats *Stats) { stats.Retried++ })
+}
+
+// interrupted devolve à fila a mensagem cujo handler foi cancelado no encerramento, sem gastar a tentativa.
+// Em filas padrão a mensagem é reenviada com as tentativas anteriores no atributo attemptsAttribute, o que
+// também zera o contador da RedrivePolicy; em filas FIFO o reenvio a colocaria depois das mensagens seguintes
+// do grupo, então ela só volta a ficar visível e esse recebimento continua contando no ApproximateReceiveCount.
+// O reenvio também não é possível quando a mensagem já usa todos os atributos permitidos
+func (c *Consumer) interrupted(ctx context.Context, message types.Message, attempt int) {
+	defer c.update(func(stats *Stats) { stats.Interrupted++ })
+
+	attributes := withoutAttempts(message.MessageAttributes)
+	if !strings.HasSuffix(c.queue, ".fifo") && len(attributes) < maxMessageAttributes {
+		resent := make(map[string]types.MessageAttributeValue, len(attributes)+1)
+		for name, value := range attributes {
+			resent[name] = value
+		}
+		resent[attemptsAttribute] = types.MessageAttributeValue{
+			DataType:    aws.String("Number"),
+			StringValue: aws.String(strconv.Itoa(attempt - 1)),
+		}
+		_, err := c.client.SendMessage(ctx, &sqs.SendMessageInput{
+			QueueUrl:          aws.String(c.queueURL),
+			MessageBody:       message.Body,
+			MessageAttributes: resent,
+		})
+		if err == nil {
+			// Se a remoção falhar a mensagem original volta após o VisibilityTimeout e é processada duas vezes
+			if err := c.delete(ctx, message); err != nil {
+				log.Printf("Consumidor SQS %s: erro ao remover mensagem interrompida %s após reenviá-la: %v", c.queue, aws.ToString(message.MessageId), err)
+				c.recordError(err)
+			}
+			return
+		}
+		log.Printf("Consumidor SQS %s: erro ao reenviar mensagem interrompida %s: %v", c.queue, aws.ToString(message.MessageId), err)
+		c.recordError(err)
+	}
+
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(c.queueURL),
+		ReceiptHandle:     message.ReceiptHandle,
+		VisibilityTimeout: 0,
+	})
+	if err != nil {
+		log.Printf("Consumidor SQS %s: erro ao devolver mensagem interrompida %s: %v", c.queue, aws.ToString(message.MessageId), err)
+		c.recordError(err)
+	}
+}
+
+// poison move a mensagem para a fila poison, quando configurada, e a remove da fila de origem
+func (c *Consumer) poison(ctx context.Context, message types.Message, attempt int, cause error) {
+	log.Printf("Consumidor SQS %s: mensagem %s tratada como poison após %d tentativa(s): %v", c.queue, aws.ToString(message.MessageId), attempt, cause)
+
+	if c.poisonQueueURL != "" {
+		input := &sqs.SendMessageInput{
+			QueueUrl:          aws.String(c.poisonQueueURL),
+			MessageBody:       message.Body,
+			MessageAttributes: withoutAttempts(message.MessageAttributes),
+		}
+		if strings.HasSuffix(c.opts.PoisonQueue, ".fifo") {
+			// Uma chave nova a cada envio: com o ID da mensagem, um segundo envio em menos de 5 minutos seria
+			// descartado como duplicata e a mensagem, removida da origem em seguida, se perderia
+			input.MessageGroupId = aws.String(message.Attributes[string(types.MessageSystemAttributeNameMessageGroupId)])
+			input.MessageDeduplicationId = aws.String(aws.ToString(message.MessageId) + "-" + uuid.New().String())
+		}
+		if _, err := c.client.SendMessage(ctx, input); err != nil {
+			// Sem a cópia na fila poison a mensagem é mantida na origem para não ser perdida
+			log.Printf("Consumidor SQS %s: erro ao enviar mensagem %s para %s: %v", c.queue, aws.ToString(message.MessageId), c.opts.PoisonQueue, err)
+			c.recordError(err)
+			c.retry(ctx, message, c.opts.Backoff(attempt))
+			return
+		}
+	}
+
+	if err := c.delete(ctx, message); err != nil {
+		log.Printf("Consumidor SQS %s: erro ao remover mensagem poison %s: %v", c.queue, aws.ToString(message.MessageId), err)
+		c.recordError(err)
+	}
+	c.update(func(stats *Stats) { stats.Poisoned++ })
+}
@@ -0,0 +1,115 @@
+package sqsconsumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// Tempo concedido, após o cancelamento dos handlers, para as chamadas finais de confirmação ou devolução
+const cancelGracePeriod = 5 * time.Second
+
+// Manager mantém um consumidor por fila e coordena o início e o encerramento de todos
+type Manager struct {
+	client *sqs.Client
+
+	mu        sync.Mutex
+	consumers []*Consumer
+	started   bool
+}
+
+func NewManager(client *sqs.Client) *Manager {
+	return &Manager{
+		client:    client,
+		consumers: make([]*Consumer, 0),
+	}
+}
+
+// Register associa o handler à fila; deve ser chamado antes de Start
+func (m *Manager) Register(queue string, handler Handler, opts Options) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.started {
+		return fmt.Errorf("consumidores já iniciados")
+	}
+	for _, consumer := range m.consumers {
+		if consumer.queue == queue {
+			return fmt.Errorf("fila %s já possui um consumidor", queue)
+		}
+	}
+	m.consumers = append(m.consumers, newConsumer(m.client, queue, handler, opts))
+	return nil
+}
+
+// Start inicia o polling de todas as filas registradas apenas uma vez
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.started {
+		return
+	}
+	m.started = true
+	for _, consumer := range m.consumers {
+		consumer.start()
+	}
+}
+
+// Shutdown para de receber mensagens e aguarda as que estão em processamento. Se o contexto expirar
+// antes, os handlers são cancelados e aguardados por até cancelGracePeriod; as mensagens não confirmadas
+// voltam à fila após o visibility timeout
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	consumers := m.consumers
+	started := m.started
+	m.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	for _, consumer := range consumers {
+		consumer.update(func(stats *Stats) { stats.State = StateDraining })
+		consumer.stopPolling()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		for _, consumer := range consumers {
+			<-consumer.pollerDone
+			consumer.handlers.Wait()
+			consumer.update(func(stats *Stats) { stats.State = StateStopped })
+		}
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		for _, consumer := range consumers {
+			consumer.cancelHandlers()
+		}
+		// Os handlers cancelados ainda confirmam ou devolvem suas mensagens antes de terminar
+		select {
+		case <-drained:
+		case <-time.After(cancelGracePeriod):
+		}
+		return fmt.Errorf("encerramento dos consumidores interrompido: %v", ctx.Err())
+	}
+}
+
+// Stats retorna o estado de cada consumidor, na ordem de registro
+func (m *Manager) Stats() []Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]Stats, 0, len(m.consumers))
+	for _, consumer := range m.consumers {
+		stats = append(stats, consumer.Stats())
+	}
+	return stats
+}